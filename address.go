@@ -0,0 +1,205 @@
+package upsfreight
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+//countryNames maps the ways a country is commonly written in an address to its two character code
+var countryNames = map[string]string{
+	"US":            "US",
+	"USA":           "US",
+	"U.S.":          "US",
+	"U.S.A.":        "US",
+	"UNITED STATES": "US",
+	"CA":            "CA",
+	"CAN":           "CA",
+	"CANADA":        "CA",
+}
+
+//postal code formats
+var (
+	usPostalCode     = regexp.MustCompile(`^\d{5}(-\d{4})?$`)
+	canadaPostalCode = regexp.MustCompile(`^[A-Za-z]\d[A-Za-z] ?\d[A-Za-z]\d$`)
+	statePostalCode  = regexp.MustCompile(`^([A-Za-z]{2})\s+(\d{5}(?:-\d{4})?|[A-Za-z]\d[A-Za-z] ?\d[A-Za-z]\d)$`)
+)
+
+//ParseAddress does a best effort parse of a single line US or Canada address into an Address
+//Commas must separate the street, city, and state/postal code, i.e. "123 Main St, Suite 4, Springfield, IL 62701"
+//or "100 Queen St W, Toronto, ON M5H 2N2, Canada".  A multi line address, i.e. from a mailing label, can
+//be given too, line breaks are treated the same as commas.  The country is read from the end of the line if
+//present, otherwise it is figured out from the postal code format, otherwise defaultCountry is used.
+//An error is returned when the line can't be split confidently.  Always show the parsed address to
+//a user to confirm it is correct.
+func ParseAddress(line, defaultCountry string) (a Address, err error) {
+	parts := []string{}
+	separator := func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r'
+	}
+	for _, p := range strings.FieldsFunc(line, separator) {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+
+	//get the country if it was given
+	if len(parts) > 0 {
+		if code, ok := countryNames[strings.ToUpper(parts[len(parts)-1])]; ok && len(parts) > 3 {
+			a.CountryCode = code
+			parts = parts[:len(parts)-1]
+		}
+	}
+
+	//get the state and postal code
+	//these are usually together, "IL 62701", but are sometimes separated by a comma, "IL, 62701"
+	if len(parts) < 3 {
+		err = newError("upsfreight.ParseAddress", "address must have a street, city, and state and postal code separated by commas")
+		return
+	}
+
+	last := parts[len(parts)-1]
+	if m := statePostalCode.FindStringSubmatch(last); m != nil {
+		a.StateProvinceCode = strings.ToUpper(m[1])
+		a.PostalCode = strings.ToUpper(m[2])
+		parts = parts[:len(parts)-1]
+	} else if (usPostalCode.MatchString(last) || canadaPostalCode.MatchString(last)) && len(parts) > 3 && len(parts[len(parts)-2]) == 2 {
+		a.StateProvinceCode = strings.ToUpper(parts[len(parts)-2])
+		a.PostalCode = strings.ToUpper(last)
+		parts = parts[:len(parts)-2]
+	} else {
+		err = newError("upsfreight.ParseAddress", "could not find the state and postal code")
+		return
+	}
+
+	if !IsValidStateProvinceCode(a.StateProvinceCode) {
+		err = newError("upsfreight.ParseAddress", a.StateProvinceCode+" is not a valid state or province code")
+		return
+	}
+
+	//get the city and street
+	if len(parts) < 2 {
+		err = newError("upsfreight.ParseAddress", "could not find the street and city")
+		return
+	}
+
+	a.City = parts[len(parts)-1]
+	a.AddressLine = strings.Join(parts[:len(parts)-1], ", ")
+
+	//figure out the country if it wasn't given
+	if a.CountryCode == "" {
+		switch {
+		case usPostalCode.MatchString(a.PostalCode):
+			a.CountryCode = "US"
+		case canadaPostalCode.MatchString(a.PostalCode):
+			a.CountryCode = "CA"
+		default:
+			a.CountryCode = strings.ToUpper(defaultCountry)
+		}
+	}
+
+	//make sure the state belongs to the country
+	if !isValidStateProvinceCodeForCountry(a.StateProvinceCode, a.CountryCode) {
+		err = newError("upsfreight.ParseAddress", a.StateProvinceCode+" is not a valid state or province code for "+a.CountryCode)
+		return
+	}
+
+	return
+}
+
+//maxAddressLines is the most address lines UPS accepts
+const maxAddressLines = 3
+
+//Lines returns the address lines that are set, AddressLine first
+func (a Address) Lines() []string {
+	lines := []string{a.AddressLine}
+	for _, l := range []string{a.AddressLine2, a.AddressLine3} {
+		if strings.TrimSpace(l) != "" {
+			lines = append(lines, l)
+		}
+	}
+
+	return lines
+}
+
+//positionalLines returns the address lines up to the last one that is not empty
+//Empty lines before it are kept so each line keeps its position, i.e. an address with only AddressLine3
+//set is decoded with the line in AddressLine3, not AddressLine2.  Lines of only spaces are kept as is.
+func (a Address) positionalLines() []string {
+	lines := []string{a.AddressLine, a.AddressLine2, a.AddressLine3}
+	for len(lines) > 1 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+//addressJSON is an Address without its json methods, used to encode and decode the other fields
+type addressJSON Address
+
+//MarshalJSON encodes the address in the format UPS expects
+//UPS accepts AddressLine as either one line or a list of lines.  One line is sent as is, a string, so
+//addresses without a second or third line are sent exactly as they always were.  A list keeps each
+//line in its position, see positionalLines, so the address decodes to the same lines.
+func (a Address) MarshalJSON() ([]byte, error) {
+	lines := a.positionalLines()
+	if len(lines) == 1 {
+		return json.Marshal(addressJSON(a))
+	}
+
+	//the outer AddressLine replaces the one in addressJSON
+	return json.Marshal(struct {
+		AddressLine []string
+		addressJSON
+	}{
+		AddressLine: lines,
+		addressJSON: addressJSON(a),
+	})
+}
+
+//UnmarshalJSON decodes an address with AddressLine as either one line or a list of lines
+func (a *Address) UnmarshalJSON(data []byte) error {
+	var decoded struct {
+		AddressLine json.RawMessage
+		addressJSON
+	}
+	err := json.Unmarshal(data, &decoded)
+	if err != nil {
+		return err
+	}
+
+	*a = Address(decoded.addressJSON)
+
+	//decode the address lines
+	var lines []string
+	raw := strings.TrimSpace(string(decoded.AddressLine))
+	switch {
+	case raw == "" || raw == "null":
+		return nil
+	case strings.HasPrefix(raw, "["):
+		err = json.Unmarshal(decoded.AddressLine, &lines)
+	default:
+		lines = make([]string, 1)
+		err = json.Unmarshal(decoded.AddressLine, &lines[0])
+	}
+	if err != nil {
+		return wrapError(err, "upsfreight.Address", "invalid AddressLine")
+	}
+	if len(lines) > maxAddressLines {
+		return newError("upsfreight.Address", "AddressLine can have at most 3 lines")
+	}
+
+	for i, l := range lines {
+		switch i {
+		case 0:
+			a.AddressLine = l
+		case 1:
+			a.AddressLine2 = l
+		case 2:
+			a.AddressLine3 = l
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package upsfreight
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+//addressRoundTripTests are addresses that must decode to exactly the lines they were encoded with
+var addressRoundTripTests = []struct {
+	name    string
+	address Address
+}{
+	{"one line", Address{AddressLine: "1000 Semmes Ave", City: "Richmond"}},
+	{"two lines", Address{AddressLine: "1000 Semmes Ave", AddressLine2: "Suite 200", City: "Richmond"}},
+	{"three lines", Address{AddressLine: "1000 Semmes Ave", AddressLine2: "Suite 200", AddressLine3: "Dock 4", City: "Richmond"}},
+	{"only line 3", Address{AddressLine: "1000 Semmes Ave", AddressLine3: "Dock 4", City: "Richmond"}},
+	{"whitespace line 2", Address{AddressLine: "1000 Semmes Ave", AddressLine2: "   ", City: "Richmond"}},
+	{"whitespace line 2 and line 3", Address{AddressLine: "1000 Semmes Ave", AddressLine2: " \t", AddressLine3: "Dock 4", City: "Richmond"}},
+	{"blank street", Address{AddressLine3: "Dock 4", City: "Richmond"}},
+}
+
+func TestAddressJSONRoundTrip(t *testing.T) {
+	for _, tt := range addressRoundTripTests {
+		b, err := json.Marshal(tt.address)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		var decoded Address
+		if err := json.Unmarshal(b, &decoded); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		if decoded != tt.address {
+			t.Errorf("%s: expected %+v, got %+v from %s", tt.name, tt.address, decoded, b)
+		}
+	}
+}
+
+func TestAddressStoreRoundTrip(t *testing.T) {
+	for _, tt := range addressRoundTripTests {
+		var prd PickupRequestDetails
+		prd.ShipFrom.Address = tt.address
+		prd.ShipTo.Address = tt.address
+
+		b, err := prd.MarshalStore()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		var decoded PickupRequestDetails
+		if err := decoded.UnmarshalStore(b); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		if decoded.ShipFrom.Address != tt.address {
+			t.Errorf("%s: expected ship from %+v, got %+v", tt.name, tt.address, decoded.ShipFrom.Address)
+		}
+		if decoded.ShipTo.Address != tt.address {
+			t.Errorf("%s: expected ship to %+v, got %+v", tt.name, tt.address, decoded.ShipTo.Address)
+		}
+	}
+}
+
+func TestAddressSingleLineIsString(t *testing.T) {
+	b, err := json.Marshal(Address{AddressLine: "1000 Semmes Ave"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fields["AddressLine"].(string); !ok {
+		t.Fatalf("expected AddressLine to be a string, got %s", b)
+	}
+}
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected Address
+	}{
+		{
+			"123 Main St, Springfield, IL 62701",
+			Address{AddressLine: "123 Main St", City: "Springfield", StateProvinceCode: "IL", PostalCode: "62701", CountryCode: "US"},
+		},
+		{
+			"123 Main St, Suite 4, Springfield, il 62701-6789",
+			Address{AddressLine: "123 Main St, Suite 4", City: "Springfield", StateProvinceCode: "IL", PostalCode: "62701-6789", CountryCode: "US"},
+		},
+		{
+			"123 Main St, Springfield, IL, 62701, USA",
+			Address{AddressLine: "123 Main St", City: "Springfield", StateProvinceCode: "IL", PostalCode: "62701", CountryCode: "US"},
+		},
+		{
+			"123 Main St\nSuite 4\r\nSpringfield, IL 62701\n",
+			Address{AddressLine: "123 Main St, Suite 4", City: "Springfield", StateProvinceCode: "IL", PostalCode: "62701", CountryCode: "US"},
+		},
+		{
+			"100 Queen St W, Toronto, ON M5H 2N2, Canada",
+			Address{AddressLine: "100 Queen St W", City: "Toronto", StateProvinceCode: "ON", PostalCode: "M5H 2N2", CountryCode: "CA"},
+		},
+		{
+			"100 Queen St W\nToronto, ON m5h2n2",
+			Address{AddressLine: "100 Queen St W", City: "Toronto", StateProvinceCode: "ON", PostalCode: "M5H2N2", CountryCode: "CA"},
+		},
+	}
+
+	for _, tt := range tests {
+		a, err := ParseAddress(tt.line, "US")
+		if err != nil {
+			t.Errorf("%q: %v", tt.line, err)
+			continue
+		}
+		if a != tt.expected {
+			t.Errorf("%q: expected %+v, got %+v", tt.line, tt.expected, a)
+		}
+	}
+}
+
+func TestParseAddressUnparseable(t *testing.T) {
+	for _, line := range []string{
+		"",
+		"123 Main St Springfield IL 62701",
+		"Springfield, IL 62701",
+		"123 Main St, Springfield, Illinois 62701",
+		"123 Main St, Springfield, IL 627",
+		"123 Main St, Springfield, ZZ 62701",
+		"123 Main St, Springfield, ON 62701",
+		"100 Queen St W, Toronto, IL M5H 2N2",
+	} {
+		if a, err := ParseAddress(line, "US"); err == nil {
+			t.Errorf("%q: expected an error, got %+v", line, a)
+		}
+	}
+}
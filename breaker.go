@@ -0,0 +1,138 @@
+package upsfreight
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+//ErrCircuitOpen is returned, without calling UPS, while the circuit breaker is open
+//The circuit breaker opens after too many calls to UPS failed in a row, see SetCircuitBreaker.  Do not
+//retry right away when you get this error, wait for the cooldown.
+var ErrCircuitOpen = errors.New("upsfreight - circuit breaker open, UPS calls are paused after repeated failures")
+
+//CircuitState is the state of a client's circuit breaker
+type CircuitState int
+
+//circuit breaker states
+const (
+	CircuitClosed   CircuitState = iota //calls to UPS are made normally
+	CircuitOpen                         //calls to UPS are not made, ErrCircuitOpen is returned instead
+	CircuitHalfOpen                     //the cooldown passed and one call is being made to see if UPS recovered
+)
+
+//String returns the name of the state for logging
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+//breaker stops calls to UPS after repeated failures
+type breaker struct {
+	mu sync.Mutex
+
+	threshold int           //failures in a row that open the breaker, 0 means the breaker is off
+	cooldown  time.Duration //how long the breaker stays open before a call is let through
+
+	state    CircuitState
+	failures int       //failures in a row
+	openedAt time.Time //when the breaker last opened
+
+	now func() time.Time //the current time, replaced in tests; time.Now is used if nil
+}
+
+//clock returns the current time using the breaker's clock
+func (b *breaker) clock() time.Time {
+	if b.now == nil {
+		return time.Now()
+	}
+
+	return b.now()
+}
+
+//SetCircuitBreaker turns on stopping calls to UPS after repeated failures
+//After threshold calls in a row fail, because UPS could not be reached, was down for maintenance, or
+//was rate limiting, calls return ErrCircuitOpen without calling UPS.  Once the cooldown has passed one
+//call is let through, if it succeeds calls are made normally again, if it fails the breaker opens for
+//another cooldown.  Faults from UPS about the request itself, i.e. invalid data, are not failures.
+//Set threshold to 0 to turn off the circuit breaker, this is the default.
+func (c *Client) SetCircuitBreaker(threshold int, cooldown time.Duration) {
+	c.breaker.mu.Lock()
+	defer c.breaker.mu.Unlock()
+
+	c.breaker.threshold = threshold
+	c.breaker.cooldown = cooldown
+	c.breaker.state = CircuitClosed
+	c.breaker.failures = 0
+
+	return
+}
+
+//CircuitState returns the current state of the circuit breaker
+func (c *Client) CircuitState() CircuitState {
+	c.breaker.mu.Lock()
+	defer c.breaker.mu.Unlock()
+
+	if c.breaker.state == CircuitOpen && c.breaker.clock().Sub(c.breaker.openedAt) >= c.breaker.cooldown {
+		return CircuitHalfOpen
+	}
+
+	return c.breaker.state
+}
+
+//allow checks if a call to UPS can be made
+//When the cooldown has passed the breaker moves to half open and lets this one call through.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.threshold <= 0 {
+		return true
+	}
+
+	switch b.state {
+	case CircuitOpen:
+		if b.clock().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+
+		b.state = CircuitHalfOpen
+		return true
+	case CircuitHalfOpen:
+		//a call is already checking if UPS recovered
+		return false
+	default:
+		return true
+	}
+}
+
+//record saves the outcome of a call to UPS
+func (b *breaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.threshold <= 0 {
+		return
+	}
+
+	if !failed {
+		b.state = CircuitClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == CircuitHalfOpen || b.failures >= b.threshold {
+		b.state = CircuitOpen
+		b.openedAt = b.clock()
+	}
+
+	return
+}
@@ -0,0 +1,151 @@
+package upsfreight
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+//newBreakerClient returns a client with a circuit breaker, a clock the test moves, and a transport
+//that fails while down is true
+func newBreakerClient(t *testing.T, threshold int, cooldown time.Duration) (c *Client, now *time.Time, down *bool, calls *int) {
+	now, down, calls = new(time.Time), new(bool), new(int)
+	*now = time.Date(2030, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	success := readFixture(t, "pickup_success.json")
+	c = NewClient("testuser", "testpassword", "testaccesskey")
+	c.SetTransport(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		*calls++
+		if *down {
+			return nil, errors.New("connection refused")
+		}
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(bytes.NewReader(success)),
+			Request:    r,
+		}, nil
+	}))
+
+	c.SetCircuitBreaker(threshold, cooldown)
+	c.breaker.now = func() time.Time {
+		return *now
+	}
+
+	return
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	c, now, down, calls := newBreakerClient(t, 3, time.Minute)
+	prd := testPickup(t)
+
+	//closed to open after 3 failures in a row
+	*down = true
+	for i := 0; i < 3; i++ {
+		if c.CircuitState() != CircuitClosed {
+			t.Fatalf("failure %d: expected the breaker to be closed, got %s", i, c.CircuitState())
+		}
+		if _, err := c.RequestPickup(&prd); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("failure %d: expected the call to UPS to fail, got %v", i, err)
+		}
+	}
+	if c.CircuitState() != CircuitOpen {
+		t.Fatalf("expected the breaker to be open, got %s", c.CircuitState())
+	}
+
+	//open rejects calls without calling UPS
+	*down = false
+	if _, err := c.RequestPickup(&prd); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if *calls != 3 {
+		t.Fatalf("expected UPS to not be called while open, got %d calls", *calls)
+	}
+
+	//half open after the cooldown, a failure opens the breaker again
+	*now = now.Add(time.Minute)
+	if c.CircuitState() != CircuitHalfOpen {
+		t.Fatalf("expected the breaker to be half open, got %s", c.CircuitState())
+	}
+	*down = true
+	if _, err := c.RequestPickup(&prd); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the half open call to be made and fail, got %v", err)
+	}
+	if c.CircuitState() != CircuitOpen {
+		t.Fatalf("expected a half open failure to open the breaker, got %s", c.CircuitState())
+	}
+	*now = now.Add(time.Minute - time.Second)
+	if _, err := c.RequestPickup(&prd); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected a new cooldown after a half open failure, got %v", err)
+	}
+
+	//a half open success closes the breaker
+	*now = now.Add(time.Second)
+	*down = false
+	if _, err := c.RequestPickup(&prd); err != nil {
+		t.Fatalf("expected the half open call to succeed, got %v", err)
+	}
+	if c.CircuitState() != CircuitClosed {
+		t.Fatalf("expected a half open success to close the breaker, got %s", c.CircuitState())
+	}
+
+	//the failures in a row start over once closed
+	*down = true
+	for i := 0; i < 2; i++ {
+		c.RequestPickup(&prd)
+	}
+	if c.CircuitState() != CircuitClosed {
+		t.Fatalf("expected the breaker to stay closed below the threshold, got %s", c.CircuitState())
+	}
+}
+
+func TestCircuitBreakerHalfOpenAllowsOneCall(t *testing.T) {
+	c, now, down, _ := newBreakerClient(t, 1, time.Minute)
+	prd := testPickup(t)
+
+	*down = true
+	c.RequestPickup(&prd)
+	*now = now.Add(time.Minute)
+
+	if !c.breaker.allow() {
+		t.Fatal("expected the first call after the cooldown to be allowed")
+	}
+	if c.breaker.allow() {
+		t.Fatal("expected only one call to be allowed while half open")
+	}
+}
+
+func TestCircuitBreakerIgnoresRequestErrors(t *testing.T) {
+	c, _, _, calls := newBreakerClient(t, 1, time.Minute)
+
+	//invalid details are rejected before calling UPS
+	invalid := testPickup(t)
+	invalid.Requester.Name = ""
+	for i := 0; i < 3; i++ {
+		var validationErr *ValidationError
+		if _, err := c.RequestPickup(&invalid); !errors.As(err, &validationErr) {
+			t.Fatalf("expected a *ValidationError, got %v", err)
+		}
+	}
+	if c.CircuitState() != CircuitClosed || *calls != 0 {
+		t.Fatalf("expected validation errors to not open the breaker, got %s after %d calls", c.CircuitState(), *calls)
+	}
+
+	//faults about the request itself mean UPS is up
+	fault := newTestClient(t, fixtureHandler(t, "pickup_fault.json"))
+	fault.SetCircuitBreaker(1, time.Minute)
+	prd := testPickup(t)
+	for i := 0; i < 3; i++ {
+		var faultErr *UPSFaultError
+		if _, err := fault.RequestPickup(&prd); !errors.As(err, &faultErr) {
+			t.Fatalf("expected a *UPSFaultError, got %v", err)
+		}
+	}
+	if fault.CircuitState() != CircuitClosed {
+		t.Fatalf("expected UPS faults to not open the breaker, got %s", fault.CircuitState())
+	}
+}
@@ -0,0 +1,103 @@
+package upsfreight
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//maxBulkLineSize is the longest line, in bytes, RequestPickupsFromReader will read
+const maxBulkLineSize = 1 << 20
+
+//PickupResult is the outcome of requesting one pickup read by RequestPickupsFromReader
+//Line is the line number in the input, starting at 1.  Err is set if the line could not be decoded
+//or the pickup could not be requested, otherwise Response holds the confirmation.  Details is nil if
+//the line could not be decoded.
+type PickupResult struct {
+	Line     int
+	Details  *PickupRequestDetails
+	Response PickupRequestResponse
+	Err      error
+}
+
+//RequestPickupsFromReader requests a pickup for each line of newline delimited json, NDJSON
+//Each line is the json of one PickupRequestDetails, in the format sent to UPS, so fields that are not
+//sent to UPS, i.e. ShipTo, can't be given.  Blank lines are skipped.  Up to concurrency pickups are
+//requested at the same time, less than 1 means one at a time.  Each pickup is validated and requested
+//with RequestPickup.
+//A line that can't be decoded gets a result with the decode error, the rest of the lines are still
+//requested.  The results are in the order of the lines.  An error is returned if the input could not
+//be read, with the results for the lines read so far, or if any pickup failed, check the results for
+//which ones.
+func (c *Client) RequestPickupsFromReader(r io.Reader, concurrency int) (results []PickupResult, err error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	//read each line and request the pickups as they are read
+	//each pickup writes to its own result so the pickups can finish in any order
+	var (
+		wg      sync.WaitGroup
+		lineNum int
+		sem     = make(chan struct{}, concurrency)
+		pending []*PickupResult
+	)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxBulkLineSize)
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		result := &PickupResult{
+			Line: lineNum,
+		}
+		pending = append(pending, result)
+
+		var prd PickupRequestDetails
+		decodeErr := json.Unmarshal([]byte(line), &prd)
+		if decodeErr != nil {
+			result.Err = wrapError(decodeErr, "upsfreight.RequestPickupsFromReader", "could not decode line "+strconv.Itoa(lineNum))
+			continue
+		}
+		result.Details = &prd
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(result *PickupResult) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			result.Response, result.Err = c.RequestPickup(result.Details)
+		}(result)
+	}
+	wg.Wait()
+
+	results = make([]PickupResult, len(pending))
+	failed := 0
+	for i, p := range pending {
+		results[i] = *p
+		if p.Err != nil {
+			failed++
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		err = wrapError(err, "upsfreight.RequestPickupsFromReader", "could not read input")
+		return
+	}
+	if failed > 0 {
+		err = newError("upsfreight.RequestPickupsFromReader", strconv.Itoa(failed)+" of "+strconv.Itoa(len(results))+" pickups failed")
+		return
+	}
+
+	return
+}
@@ -0,0 +1,102 @@
+package upsfreight
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRequestPickupsFromReader(t *testing.T) {
+	//each pickup gets a confirmation number made from its customer context so the results can be
+	//matched to the lines
+	var inFlight, maxInFlight int32
+	success := readFixture(t, "pickup_success.json")
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			seen := atomic.LoadInt32(&maxInFlight)
+			if n <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, n) {
+				break
+			}
+		}
+
+		var req PickupRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		customerContext := req.FreightPickupRequest.Request.TransactionReference.CustomerContext
+
+		//later lines finish first so the results must be put back in order
+		line, _ := strconv.Atoi(strings.TrimPrefix(customerContext, "line-"))
+		time.Sleep(time.Duration(10-line) * 5 * time.Millisecond)
+
+		body := bytes.Replace(success, []byte("upsfreight-selftest"), []byte(customerContext), 1)
+		body = bytes.Replace(body, []byte("WBU2805291"), []byte("WBU10000"+strconv.Itoa(line)), 1)
+		w.Write(body)
+	})
+
+	var input bytes.Buffer
+	for line := 1; line <= 8; line++ {
+		switch line {
+		case 3:
+			input.WriteString("\n")
+		case 5:
+			input.WriteString("{not json\n")
+		default:
+			prd := testPickup(t)
+			prd.SetCustomerContext("line-" + strconv.Itoa(line))
+			b, err := json.Marshal(prd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			input.Write(b)
+			input.WriteString("\n")
+		}
+	}
+
+	results, err := c.RequestPickupsFromReader(&input, 2)
+	if err == nil || !strings.Contains(err.Error(), "1 of 7 pickups failed") {
+		t.Fatalf("expected an error for the malformed line, got %v", err)
+	}
+
+	expectedLines := []int{1, 2, 4, 5, 6, 7, 8}
+	if len(results) != len(expectedLines) {
+		t.Fatalf("expected %d results, blank lines skipped, got %d", len(expectedLines), len(results))
+	}
+
+	for i, result := range results {
+		line := expectedLines[i]
+		if result.Line != line {
+			t.Errorf("result %d: expected line %d, got %d", i, line, result.Line)
+			continue
+		}
+
+		if line == 5 {
+			if result.Err == nil || result.Details != nil || !strings.Contains(result.Err.Error(), "line 5") {
+				t.Errorf("line 5: expected a decode error and no details, got %v", result.Err)
+			}
+			continue
+		}
+
+		if result.Err != nil {
+			t.Errorf("line %d: %v", line, result.Err)
+			continue
+		}
+		if got := result.Response.FreightPickupResponse.PickupRequestConfirmationNumber; got != "WBU10000"+strconv.Itoa(line) {
+			t.Errorf("line %d: expected the line's confirmation number, got %s", line, got)
+		}
+		if got := result.Details.Request.TransactionReference.CustomerContext; got != "line-"+strconv.Itoa(line) {
+			t.Errorf("line %d: expected the line's details, got %s", line, got)
+		}
+	}
+
+	if seen := atomic.LoadInt32(&maxInFlight); seen > 2 {
+		t.Errorf("expected at most 2 pickups at the same time, got %d", seen)
+	}
+}
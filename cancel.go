@@ -0,0 +1,195 @@
+package upsfreight
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+//CancelPickupRequest is the main container struct for data sent to UPS to cancel a pickup
+//This is sent to the same url as a pickup request, UPS knows this is a cancellation based on the
+//FreightCancelPickupRequest field.
+type CancelPickupRequest struct {
+	Security                   Security
+	FreightCancelPickupRequest CancelPickupRequestDetails
+}
+
+//CancelPickupRequestDetails is the container around the actual cancellation
+type CancelPickupRequestDetails struct {
+	Request RequestHeader
+
+	PickupRequestConfirmationNumber string //the confirmation number returned when the pickup was requested
+}
+
+//CancelPickupResponse is the data we get back when a pickup is cancelled successfully
+type CancelPickupResponse struct {
+	FreightCancelPickupResponse FreightCancelPickupResponse
+}
+
+//FreightCancelPickupResponse is the container around the actual cancellation response
+type FreightCancelPickupResponse struct {
+	Response            Response
+	FreightCancelStatus FreightCancelStatus
+}
+
+//FreightCancelStatus is the status of the cancelled pickup, i.e. "Cancelled"
+type FreightCancelStatus struct {
+	Code        string
+	Description string
+}
+
+//redactedJSON returns the json of a cancel request with the credentials redacted
+func (cr CancelPickupRequest) redactedJSON() ([]byte, error) {
+	cr.Security.UsernameToken.Password = redacted
+	cr.Security.UPSServiceAccessToken.AccessLicenseNumber = redacted
+	return json.Marshal(cr)
+}
+
+//security returns the credentials the cancel request was built with
+func (cr CancelPickupRequest) security() Security {
+	return cr.Security
+}
+
+//responseStatusSuccess is the ResponseStatus.Code UPS returns when a request was successful
+const responseStatusSuccess = "1"
+
+//CancelPickupResult is the outcome of a successful pickup cancellation
+//UPS only returns a status for a cancellation.  The pickup date and window are filled in from the
+//pickup store, if one is set and it recorded the pickup (see SetPickupStore), otherwise they are blank.
+type CancelPickupResult struct {
+	ConfirmationNumber string //the pickup that was cancelled
+	StatusCode         string //UPS's cancellation status code
+	StatusDescription  string //UPS's cancellation status, i.e. "Cancelled"
+	PickupDate         string //YYYYMMDD
+	EarliestTimeReady  string //24 hour time, HHMM
+	LatestTimeReady    string //24 hour time, HHMM
+	TransactionID      string //UPS's id for the call, give this to UPS support when asking about the cancellation
+
+	Response CancelPickupResponse //the full response from UPS
+}
+
+//confirmation number length limits
+//UPS does not document the format of a confirmation number.  The numbers seen are letters followed by
+//digits, i.e. WBU2805291, so these limits are intentionally loose to only catch obviously bad input.
+const (
+	minConfirmationNumberLength = 6
+	maxConfirmationNumberLength = 20
+)
+
+//IsValidConfirmationNumber checks if a string looks like a pickup confirmation number
+//A valid confirmation number is 6 to 20 letters and digits with no spaces or punctuation.  This only
+//checks the format, not that UPS knows of the pickup.
+func IsValidConfirmationNumber(s string) bool {
+	if len(s) < minConfirmationNumberLength || len(s) > maxConfirmationNumberLength {
+		return false
+	}
+
+	for _, r := range s {
+		isLetter := (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z')
+		isDigit := r >= '0' && r <= '9'
+		if !isLetter && !isDigit {
+			return false
+		}
+	}
+
+	return true
+}
+
+//CancelPickup performs the call to the UPS API to cancel a previously scheduled pickup
+//confirmationNumber is the PickupRequestConfirmationNumber returned from RequestPickup.
+//If the pickup was already cancelled the error matches ErrAlreadyCancelled, if UPS does not know of
+//the pickup the error matches ErrPickupNotFound, use errors.Is to check.
+//
+//Deprecated: use Client.CancelPickup, with a client from NewClient, instead.
+func CancelPickup(confirmationNumber string) (CancelPickupResult, error) {
+	return defaultClient().CancelPickup(confirmationNumber)
+}
+
+//CancelPickup performs the call to the UPS API to cancel a previously scheduled pickup
+//See the package level CancelPickup for details.
+func (c *Client) CancelPickup(confirmationNumber string) (CancelPickupResult, error) {
+	return c.CancelPickupContext(context.Background(), confirmationNumber)
+}
+
+//CancelPickupContext is CancelPickup with a context
+//The span for the cancellation, see SetTracer, is started from ctx so it is a child of the caller's
+//span.  The call to UPS is cancelled if ctx is cancelled or its deadline passes before the timeout.
+func (c *Client) CancelPickupContext(ctx context.Context, confirmationNumber string) (result CancelPickupResult, err error) {
+	//record how long the request took and if it was successful
+	start := time.Now()
+	defer func() {
+		c.recordMetrics("CancelPickup", start, err)
+	}()
+
+	//trace the request if needed
+	ctx, span := c.startSpan(ctx, "CancelPickup")
+	defer func() {
+		span.End(err)
+	}()
+	span.SetAttribute(AttributeConfirmationNumber, confirmationNumber)
+
+	if confirmationNumber == "" {
+		err = newValidationError("upsfreight.CancelPickup", "confirmationNumber", "is required")
+		return
+	}
+	if !IsValidConfirmationNumber(confirmationNumber) {
+		err = newValidationError("upsfreight.CancelPickup", "confirmationNumber", "is not a valid confirmation number, it must be 6 to 20 letters and digits")
+		return
+	}
+
+	//build the request
+	cancelRequest := CancelPickupRequest{
+		Security: c.getCredentials(),
+	}
+	cancelRequest.FreightCancelPickupRequest.PickupRequestConfirmationNumber = confirmationNumber
+	cancelRequest.FreightCancelPickupRequest.Request.TransactionReference.CustomerContext = "cancel-" + confirmationNumber
+
+	//make the call to UPS
+	body, transID, err := c.callUPS(ctx, "upsfreight.CancelPickup", cancelRequest)
+	if err != nil {
+		return
+	}
+
+	//decode the response
+	var responseData CancelPickupResponse
+	if !isFault(body) {
+		err = decodeJSON(body, &responseData, c.strictDecoding)
+		if err != nil {
+			err = wrapError(err, "upsfreight.CancelPickup", "could not unmarshal response")
+			return
+		}
+	}
+
+	//check if the cancellation was successful
+	if responseData.FreightCancelPickupResponse.Response.ResponseStatus.Code != responseStatusSuccess {
+		c.logFailure("upsfreight.CancelPickup", "pickup cancellation failed", body)
+
+		var errorData PickupRequestError
+		json.Unmarshal(body, &errorData)
+
+		fault := newFaultError("upsfreight.CancelPickup", errorData)
+		fault.TransactionID = transID
+		err = fault
+		return
+	}
+
+	//build the result
+	result = CancelPickupResult{
+		ConfirmationNumber: confirmationNumber,
+		StatusCode:         responseData.FreightCancelPickupResponse.FreightCancelStatus.Code,
+		StatusDescription:  responseData.FreightCancelPickupResponse.FreightCancelStatus.Description,
+		TransactionID:      transID,
+		Response:           responseData,
+	}
+
+	//fill in what was cancelled if we recorded the pickup
+	if finder, ok := c.pickupStore.(PickupFinder); ok {
+		if p, found, findErr := finder.Find(confirmationNumber); findErr == nil && found {
+			result.PickupDate = p.Details.PickupDate
+			result.EarliestTimeReady = p.Details.EarliestTimeReady
+			result.LatestTimeReady = p.Details.LatestTimeReady
+		}
+	}
+
+	return
+}
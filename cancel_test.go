@@ -0,0 +1,63 @@
+package upsfreight
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCancelPickup(t *testing.T) {
+	c := newTestClient(t, fixtureHandler(t, "cancel_success.json"))
+
+	result, err := c.CancelPickup("WBU2805291")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.ConfirmationNumber != "WBU2805291" {
+		t.Errorf("expected confirmation number WBU2805291, got %q", result.ConfirmationNumber)
+	}
+	if result.StatusCode != "1" || result.StatusDescription != "Cancelled" {
+		t.Errorf("expected status 1 Cancelled, got %q %q", result.StatusCode, result.StatusDescription)
+	}
+}
+
+func TestCancelPickupFaults(t *testing.T) {
+	tests := []struct {
+		fixture  string
+		expected error
+		other    error
+	}{
+		{"cancel_already_cancelled.json", ErrAlreadyCancelled, ErrPickupNotFound},
+		{"cancel_not_found.json", ErrPickupNotFound, ErrAlreadyCancelled},
+	}
+
+	for _, tt := range tests {
+		c := newTestClient(t, fixtureHandler(t, tt.fixture))
+
+		_, err := c.CancelPickup("WBU2805291")
+		if !errors.Is(err, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.fixture, tt.expected, err)
+		}
+		if errors.Is(err, tt.other) {
+			t.Errorf("%s: did not expect %v", tt.fixture, tt.other)
+		}
+
+		var fault *UPSFaultError
+		if !errors.As(err, &fault) {
+			t.Errorf("%s: expected a *UPSFaultError, got %T", tt.fixture, err)
+		}
+	}
+}
+
+func TestCancelPickupInvalidConfirmationNumber(t *testing.T) {
+	c := newTestClient(t, fixtureHandler(t, "cancel_success.json"))
+
+	for _, number := range []string{"", "WBU", "WBU 2805291", "WBU-2805291"} {
+		_, err := c.CancelPickup(number)
+
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("%q: expected a *ValidationError, got %v", number, err)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package upsfreight
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+//CassetteMode is whether a Cassette records responses from UPS or replays recorded responses
+type CassetteMode int
+
+//cassette modes
+const (
+	CassetteReplay CassetteMode = iota //return recorded responses, requests are never sent
+	CassetteRecord                     //send requests and record the responses
+)
+
+//ErrNotRecorded is returned by a Cassette in replay mode when a request was not recorded
+var ErrNotRecorded = errors.New("upsfreight - request was not recorded in the cassette")
+
+//Cassette is an http.RoundTripper that records responses from UPS to a file and replays them
+//Use this with SetTransport to run tests against real UPS responses without network access.  Record
+//once against the UPS test url, commit the file, and replay in CI.
+//Requests are matched on the method, url, and body with the credentials (the Security block) removed so
+//replaying works with any credentials.  Everything else in the body must be the same as when it was
+//recorded so set the CustomerContext and use fixed pickup dates in tests.  The file holds the raw
+//responses from UPS, review it before committing it.
+type Cassette struct {
+	path      string
+	mode      CassetteMode
+	transport http.RoundTripper //where requests are sent when recording
+
+	mu        sync.Mutex
+	responses map[string]cassetteResponse //request fingerprint to response
+}
+
+//cassetteResponse is a recorded response as it is saved in the cassette file
+type cassetteResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+//NewCassette returns a cassette that reads and writes recorded responses to the file at path
+//In replay mode the file must exist.  In record mode the file is created if it does not exist and
+//responses are added to it as requests are made, requests are sent using http.DefaultTransport.
+func NewCassette(path string, mode CassetteMode) (*Cassette, error) {
+	c := &Cassette{
+		path:      path,
+		mode:      mode,
+		transport: http.DefaultTransport,
+		responses: map[string]cassetteResponse{},
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) && mode == CassetteRecord {
+		return c, nil
+	} else if err != nil {
+		return nil, wrapError(err, "upsfreight.NewCassette", "could not read file")
+	}
+
+	err = json.Unmarshal(data, &c.responses)
+	if err != nil {
+		return nil, wrapError(err, "upsfreight.NewCassette", "could not parse file")
+	}
+
+	return c, nil
+}
+
+//RoundTrip implements http.RoundTripper
+//The request is not changed, a copy of it is sent when recording.
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	//read the body so it can be fingerprinted and still sent
+	//the body is closed, as every RoundTripper must, but not replaced on the caller's request
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, wrapError(err, "upsfreight.Cassette", "could not read request")
+		}
+	}
+
+	key := cassetteKey(req.Method, req.URL.String(), reqBody)
+
+	if c.mode == CassetteReplay {
+		c.mu.Lock()
+		r, found := c.responses[key]
+		c.mu.Unlock()
+
+		if !found {
+			return nil, ErrNotRecorded
+		}
+
+		return r.toResponse(req), nil
+	}
+
+	//send a copy of the request, with the body that was read, and record the response
+	out := req.Clone(req.Context())
+	if req.Body != nil {
+		out.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	res, err := c.transport.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, wrapError(err, "upsfreight.Cassette", "could not read response")
+	}
+
+	r := cassetteResponse{
+		StatusCode: res.StatusCode,
+		Header:     res.Header,
+		Body:       body,
+	}
+
+	err = c.save(key, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.toResponse(req), nil
+}
+
+//save adds a response to the cassette and writes the cassette file
+func (c *Cassette) save(key string, r cassetteResponse) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.responses[key] = r
+
+	data, err := json.MarshalIndent(c.responses, "", "  ")
+	if err != nil {
+		return wrapError(err, "upsfreight.Cassette", "could not encode cassette")
+	}
+
+	err = ioutil.WriteFile(c.path, data, 0600)
+	if err != nil {
+		return wrapError(err, "upsfreight.Cassette", "could not write file")
+	}
+
+	return nil
+}
+
+//toResponse builds an http response from a recorded response
+func (r cassetteResponse) toResponse(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        http.StatusText(r.StatusCode),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.Header,
+		Body:          ioutil.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+//cassetteKey returns the fingerprint a request is recorded under
+//The Security block is removed from json bodies so the credentials don't affect matching.
+func cassetteKey(method, url string, body []byte) string {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(body, &fields) == nil {
+		delete(fields, "Security")
+		body, _ = json.Marshal(fields)
+	}
+
+	sum := sha256.Sum256([]byte(method + " " + url + "\n" + string(body)))
+	return hex.EncodeToString(sum[:])
+}
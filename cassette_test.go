@@ -0,0 +1,97 @@
+package upsfreight
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	var calls int32
+	success := readFixture(t, "pickup_success.json")
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("X-Ups-Transactionid", "8a3e1f0c52d64b7f")
+		w.Write(success)
+	})
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	recorder, err := NewCassette(path, CassetteRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetTransport(recorder)
+
+	prd := testPickup(t)
+	prd.SetCustomerContext("cassette-test")
+	recorded, err := c.RequestPickup(&prd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//replay with other credentials, the server must not be called
+	player, err := NewCassette(path, CassetteReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetTransport(player)
+	c.SetCredentials("otheruser", "otherpassword", "otheraccesskey")
+
+	replayed, err := c.RequestPickup(&prd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("expected UPS to be called once, while recording, got %d calls", calls)
+	}
+	if replayed.FreightPickupResponse.PickupRequestConfirmationNumber != recorded.FreightPickupResponse.PickupRequestConfirmationNumber {
+		t.Errorf("expected the recorded confirmation number %q, got %q", recorded.FreightPickupResponse.PickupRequestConfirmationNumber, replayed.FreightPickupResponse.PickupRequestConfirmationNumber)
+	}
+	if replayed.TransactionID != "8a3e1f0c52d64b7f" {
+		t.Errorf("expected the recorded headers to be replayed, got transaction id %q", replayed.TransactionID)
+	}
+
+	//a request that was not recorded is an error, not a call to UPS
+	prd.PickupInstructions = "not recorded"
+	if _, err := c.RequestPickup(&prd); !errors.Is(err, ErrNotRecorded) {
+		t.Fatalf("expected ErrNotRecorded, got %v", err)
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("expected UPS to not be called when replaying, got %d calls", calls)
+	}
+}
+
+func TestCassetteReplayMissingFile(t *testing.T) {
+	if _, err := NewCassette(filepath.Join(t.TempDir(), "missing.json"), CassetteReplay); err == nil {
+		t.Fatal("expected an error for a missing cassette in replay mode")
+	}
+}
+
+func TestCassetteDoesNotChangeRequest(t *testing.T) {
+	c := newTestClient(t, fixtureHandler(t, "pickup_success.json"))
+
+	cassette, err := NewCassette(filepath.Join(t.TempDir(), "cassette.json"), CassetteRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := ioutil.NopCloser(bytes.NewReader([]byte(`{"FreightPickupRequest":{}}`)))
+	req, err := http.NewRequest(http.MethodPost, c.BaseURL(), body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := cassette.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if req.Body != body {
+		t.Error("expected the request body to not be replaced")
+	}
+}
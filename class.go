@@ -0,0 +1,111 @@
+package upsfreight
+
+import (
+	"math"
+	"strings"
+)
+
+//DimensionUnit is a unit of length
+type DimensionUnit string
+
+//dimension units
+const (
+	DimensionUnitInches      DimensionUnit = "IN"
+	DimensionUnitCentimeters DimensionUnit = "CM"
+)
+
+//Dimensions is the size of a piece or handling unit
+type Dimensions struct {
+	Length float64
+	Width  float64
+	Height float64
+	Unit   DimensionUnit //inches are used if blank
+}
+
+//unit conversions
+const (
+	centimetersPerInch = 2.54
+	cubicInchesPerFoot = 1728
+)
+
+//densityClasses is the freight class for each density, in pounds per cubic foot
+//This is the density scale NMFTA adopted in 2025.  A density at least min, and less than the next
+//entry's min, is the entry's class.
+var densityClasses = []struct {
+	min   float64
+	class string
+}{
+	{50, "50"},
+	{35, "55"},
+	{30, "60"},
+	{22.5, "65"},
+	{15, "70"},
+	{12, "85"},
+	{10, "92.5"},
+	{8, "100"},
+	{6, "125"},
+	{4, "175"},
+	{2, "250"},
+	{1, "300"},
+	{0, "400"},
+}
+
+//CubicFeet returns the volume of the dimensions in cubic feet
+func (d Dimensions) CubicFeet() (float64, error) {
+	for _, v := range []float64{d.Length, d.Width, d.Height} {
+		if v <= 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+			return 0, newError("upsfreight.Dimensions", "length, width, and height must be more than 0")
+		}
+	}
+
+	length, width, height := d.Length, d.Width, d.Height
+	switch DimensionUnit(strings.ToUpper(string(d.Unit))) {
+	case DimensionUnitInches, "":
+	case DimensionUnitCentimeters:
+		length /= centimetersPerInch
+		width /= centimetersPerInch
+		height /= centimetersPerInch
+	default:
+		return 0, newError("upsfreight.Dimensions", "unit must be "+string(DimensionUnitInches)+" or "+string(DimensionUnitCentimeters))
+	}
+
+	return length * width * height / cubicInchesPerFoot, nil
+}
+
+//ClassifyCommodity estimates the freight class of a piece from its weight and dimensions
+//The weight and dimensions are converted to pounds and inches, the density in pounds per cubic foot is
+//calculated, and the class is looked up on NMFTA's density scale.  The density is rounded to two decimal
+//places.  This is an estimate, some commodities have a class set by NMFTA that does not depend on
+//density, check the NMFC for your commodity.
+func ClassifyCommodity(weight float64, weightUnit WeightUnit, dims Dimensions) (class string, density float64, err error) {
+	if weight <= 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+		err = newError("upsfreight.ClassifyCommodity", "weight must be more than 0")
+		return
+	}
+
+	pounds := weight
+	switch WeightUnit(strings.ToUpper(string(weightUnit))) {
+	case WeightUnitPounds, "":
+	case WeightUnitKilograms:
+		pounds *= poundsPerKilogram
+	default:
+		err = newError("upsfreight.ClassifyCommodity", "weight unit must be "+string(WeightUnitPounds)+" or "+string(WeightUnitKilograms))
+		return
+	}
+
+	cubicFeet, err := dims.CubicFeet()
+	if err != nil {
+		err = wrapError(err, "upsfreight.ClassifyCommodity", "invalid dimensions")
+		return
+	}
+
+	density = math.Round(pounds/cubicFeet*100) / 100
+	for _, c := range densityClasses {
+		if density >= c.min {
+			class = c.class
+			return
+		}
+	}
+
+	return
+}
@@ -0,0 +1,127 @@
+package upsfreight
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCubicFeetRejectsInvalidDimensions(t *testing.T) {
+	tests := []Dimensions{
+		{Length: 0, Width: 40, Height: 48},
+		{Length: 48, Width: -40, Height: 48},
+		{Length: math.NaN(), Width: 40, Height: 48},
+		{Length: 48, Width: math.Inf(1), Height: 48},
+		{Length: 48, Width: 40, Height: math.Inf(-1)},
+	}
+
+	for _, d := range tests {
+		if _, err := d.CubicFeet(); err == nil {
+			t.Errorf("expected an error for %+v", d)
+		}
+		if _, _, err := ClassifyCommodity(500, WeightUnitPounds, d); err == nil {
+			t.Errorf("expected ClassifyCommodity to return an error for %+v", d)
+		}
+	}
+}
+
+func TestClassifyCommodityUnits(t *testing.T) {
+	//each combination is 100 pounds in a 1 cubic foot box, a density of 100 and class 50
+	tests := []struct {
+		weight     float64
+		weightUnit WeightUnit
+		dims       Dimensions
+	}{
+		{100, WeightUnitPounds, Dimensions{Length: 12, Width: 12, Height: 12, Unit: DimensionUnitInches}},
+		{100, "", Dimensions{Length: 12, Width: 12, Height: 12}},
+		{100 / poundsPerKilogram, WeightUnitKilograms, Dimensions{Length: 12, Width: 12, Height: 12, Unit: DimensionUnitInches}},
+		{100, WeightUnitPounds, Dimensions{Length: 30.48, Width: 30.48, Height: 30.48, Unit: DimensionUnitCentimeters}},
+		{100 / poundsPerKilogram, WeightUnitKilograms, Dimensions{Length: 30.48, Width: 30.48, Height: 30.48, Unit: DimensionUnitCentimeters}},
+		{100 / poundsPerKilogram, "kgs", Dimensions{Length: 30.48, Width: 30.48, Height: 30.48, Unit: "cm"}},
+	}
+
+	for _, tt := range tests {
+		class, density, err := ClassifyCommodity(tt.weight, tt.weightUnit, tt.dims)
+		if err != nil {
+			t.Errorf("%v %s %+v: %v", tt.weight, tt.weightUnit, tt.dims, err)
+			continue
+		}
+
+		if density != 100 || class != "50" {
+			t.Errorf("%v %s %+v: expected density 100 and class 50, got %v and %s", tt.weight, tt.weightUnit, tt.dims, density, class)
+		}
+	}
+}
+
+func TestClassifyCommodityInvalidUnits(t *testing.T) {
+	cube := Dimensions{Length: 12, Width: 12, Height: 12}
+
+	if _, _, err := ClassifyCommodity(100, "OZ", cube); err == nil {
+		t.Error("expected an error for an unknown weight unit")
+	}
+
+	cube.Unit = "FT"
+	if _, _, err := ClassifyCommodity(100, WeightUnitPounds, cube); err == nil {
+		t.Error("expected an error for an unknown dimension unit")
+	}
+
+	cube.Unit = DimensionUnitInches
+	for _, weight := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		if _, _, err := ClassifyCommodity(weight, WeightUnitPounds, cube); err == nil {
+			t.Errorf("expected an error for weight %v", weight)
+		}
+	}
+}
+
+func TestClassifyCommodityBreakpoints(t *testing.T) {
+	//a 1 cubic foot box so the density is the weight in pounds
+	cube := Dimensions{Length: 12, Width: 12, Height: 12}
+
+	for i, c := range densityClasses {
+		//at the breakpoint
+		weight := c.min
+		if weight == 0 {
+			weight = 0.5
+		}
+
+		class, _, err := ClassifyCommodity(weight, WeightUnitPounds, cube)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if class != c.class {
+			t.Errorf("density %v: expected class %s, got %s", weight, c.class, class)
+		}
+
+		//just below the breakpoint is the next, higher, class
+		if c.min == 0 {
+			continue
+		}
+
+		class, _, err = ClassifyCommodity(c.min-0.01, WeightUnitPounds, cube)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := densityClasses[i+1].class; class != expected {
+			t.Errorf("density %v: expected class %s, got %s", c.min-0.01, expected, class)
+		}
+	}
+
+	//spot check the scale so a bad edit to the table is caught
+	spot := map[float64]string{
+		60:   "50",
+		40:   "55",
+		25:   "65",
+		11:   "92.5",
+		5:    "175",
+		1.5:  "300",
+		0.25: "400",
+	}
+	for weight, expected := range spot {
+		class, _, err := ClassifyCommodity(weight, WeightUnitPounds, cube)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if class != expected {
+			t.Errorf("density %v: expected class %s, got %s", weight, expected, class)
+		}
+	}
+}
@@ -0,0 +1,1197 @@
+package upsfreight
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+//Client holds the credentials and settings used to make calls to UPS
+//Each client is independent of every other client so you can use different UPS accounts, or test and
+//production mode, at the same time.  Create a client with NewClient or NewClientFromFile.
+//The package level funcs, i.e. SetCredentials and RequestPickup, use a default client.
+type Client struct {
+	//credentials is the log in information we will use to make requests
+	//credMu protects credentials so they can be rotated while requests are being made.
+	credentials Security
+	credMu      sync.RWMutex
+
+	//url is set to the test URL by default
+	//This is changed to the production URL when the SetProductionMode func is called.  Forcing the
+	//developer to call the SetProductionMode func ensures the production URL is only used when
+	//actually needed.
+	//urlMu protects url so the mode can be changed while requests are being made.
+	url   string
+	urlMu sync.RWMutex
+
+	//timeout is the time we should wait for a reply from UPS
+	timeout time.Duration
+
+	//operationTimeouts overrides the timeout for specific operations, i.e. RequestPickup
+	//operations not in this map use timeout
+	operationTimeouts map[string]time.Duration
+
+	//auditFunc is called after every call to UPS with the request that was sent and the response that
+	//was received, nil means no auditing is done
+	auditFunc func(req, resp []byte, status int)
+
+	//metricsFunc is called after every call to UPS with the name of the operation, how long it took,
+	//and the error returned, if any
+	metricsFunc func(op string, dur time.Duration, err error)
+
+	//beforeSend and afterReceive are hooks that can modify the raw json sent to and received from UPS
+	beforeSend   func(body []byte) ([]byte, error)
+	afterReceive func(body []byte) ([]byte, error)
+
+	//strictDecoding causes responses from UPS with fields we do not know about to be rejected
+	strictDecoding bool
+
+	//verifyCustomerContext causes RequestPickup to check the customer context UPS echoes back
+	verifyCustomerContext bool
+
+	//minimumNotice is how far in advance a pickup must be scheduled
+	minimumNotice time.Duration
+
+	//failOnWarning causes RequestPickup to return an error when UPS returns warnings
+	failOnWarning bool
+
+	//retryEmptyConfirmation causes RequestPickup to retry once when UPS responds successfully without a
+	//confirmation number
+	retryEmptyConfirmation bool
+
+	//maxResponseSize is the largest response, in bytes, we will read from UPS
+	maxResponseSize int64
+
+	//minWeight and maxWeight are the bounds, in pounds, a shipment's weight must be within
+	minWeight float64
+	maxWeight float64
+
+	//businessOpen and businessClose are the hours, as time since midnight, pickups can be made
+	//normalizeSchedule moves windows inside these hours instead of returning an error
+	businessOpen      time.Duration
+	businessClose     time.Duration
+	hasBusinessHours  bool
+	normalizeSchedule bool
+
+	//checkSameLocation causes Validate to reject pickups shipping to the same postal code they ship from
+	checkSameLocation bool
+
+	//roundingMode is how SumWeights, SetHandlingUnitWeights, and TotalWeight round the total weight
+	roundingMode RoundingMode
+
+	//transport is used to make requests, nil means http.DefaultTransport, see SetTransport
+	transport http.RoundTripper
+
+	//insecureTransport is the transport SetInsecureTLS installed, so only it is removed when turned off
+	insecureTransport *http.Transport
+
+	//tracer starts a span for each call to UPS, nil means no tracing
+	tracer Tracer
+
+	//headers are added to every request, i.e. for an API gateway
+	//headerOverrides replace the headers this package sets itself, see OverrideHeader
+	headers         http.Header
+	headerOverrides http.Header
+
+	//pickupStore is where pickups are recorded as they are scheduled, nil means pickups are not recorded
+	pickupStore PickupStore
+
+	//breaker stops calls to UPS after repeated failures, see SetCircuitBreaker
+	breaker breaker
+
+	//closed is set to 1 by Close, accessed atomically
+	closed int32
+
+	//dedupe remembers recently requested pickups to catch duplicates, see SetDedupeWindow
+	dedupe dedupe
+
+	//defaultShipFrom and defaultRequester are used by RequestPickup when the pickup details don't have
+	//them, nil means no default, see SetDefaultShipFrom and SetDefaultRequester
+	defaultShipFrom  *ShipFromAddress
+	defaultRequester *Requester
+
+	//defaultCountryCode is used for addresses without a country, blank means no default, see
+	//SetDefaultCountryCode
+	defaultCountryCode string
+}
+
+//default settings for a new client
+const (
+	//defaultTimeout is the default time we should wait for a reply from UPS
+	//You may need to adjust this based on how slow connecting to UPS is for you.
+	//10 seconds is overly long, but sometimes UPS is very slow.
+	defaultTimeout = 10 * time.Second
+
+	//defaultMaxResponseSize is generous since some UPS responses include base64 encoded documents
+	defaultMaxResponseSize = 10 << 20
+
+	//defaultMinWeight and defaultMaxWeight are reasonable bounds, in pounds, for LTL freight
+	//Weights outside these bounds are almost always data entry errors, i.e. the weight was entered in
+	//the wrong unit.
+	defaultMinWeight = 1.0
+	defaultMaxWeight = 20000.0
+)
+
+//DefaultClient is the client used by the package level funcs, i.e. SetCredentials and RequestPickup
+//The package level funcs are deprecated, they only exist so existing code keeps working.  This is nil
+//until the first package level func is called, which creates it, so programs that only use clients from
+//NewClient never build it.  You can inspect this client, or replace it with one from NewClient.  If you
+//replace it before calling a package level func your client is used as is.  Replacing it while requests
+//are being made is not safe.
+var DefaultClient *Client
+
+//defaultClientOnce makes sure DefaultClient is only created once, see defaultClient
+var defaultClientOnce sync.Once
+
+//defaultClient returns DefaultClient, creating it the first time this is called if it was not set
+func defaultClient() *Client {
+	defaultClientOnce.Do(func() {
+		if DefaultClient == nil {
+			DefaultClient = newClient()
+		}
+	})
+
+	return DefaultClient
+}
+
+//newClient returns a client with the default settings and no credentials
+func newClient() *Client {
+	return &Client{
+		url:             upsTestURL,
+		timeout:         defaultTimeout,
+		maxResponseSize: defaultMaxResponseSize,
+		minWeight:       defaultMinWeight,
+		maxWeight:       defaultMaxWeight,
+	}
+}
+
+//NewClient returns a client that uses the given UPS website login and API access key
+//The client starts in test mode with the default settings.
+func NewClient(username, password, accessKey string) *Client {
+	c := newClient()
+	c.SetCredentials(username, password, accessKey)
+	return c
+}
+
+//ErrClientClosed is returned when a call to UPS is attempted with a client after Close was called
+var ErrClientClosed = errors.New("upsfreight - client is closed")
+
+//Close releases the resources held by the client
+//Idle connections held by the transport set with SetTransport or SetInsecureTLS are closed.  The
+//client can't be used after this, calls to UPS return ErrClientClosed.  http.DefaultTransport is shared
+//with the rest of your program so its connections are left alone.  Calling Close more than once is safe.
+func (c *Client) Close() error {
+	atomic.StoreInt32(&c.closed, 1)
+
+	if t, ok := c.transport.(interface{ CloseIdleConnections() }); ok {
+		t.CloseIdleConnections()
+	}
+
+	return nil
+}
+
+//credentialsFile is the format of the file read by NewClientFromFile
+type credentialsFile struct {
+	Username  string `json:"username"`
+	Password  string `json:"password"`
+	AccessKey string `json:"accessKey"`
+	Mode      string `json:"mode"`
+}
+
+//NewClientFromFile returns a client using credentials read from a json file
+//Use this when credentials are mounted as a file, i.e. a Kubernetes secret, instead of putting them in
+//environment variables where they can leak into child processes.  The file looks like:
+//	{
+//		"username": "your ups website login username",
+//		"password": "your ups website login password",
+//		"accessKey": "your ups api access key",
+//		"mode": "production"
+//	}
+//username, password, and accessKey are required.  mode is optional, see SetMode for the accepted
+//values, and defaults to test.
+func NewClientFromFile(path string) (*Client, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, wrapError(err, "upsfreight.NewClientFromFile", "could not read file")
+	}
+
+	var f credentialsFile
+	err = json.Unmarshal(data, &f)
+	if err != nil {
+		return nil, wrapError(err, "upsfreight.NewClientFromFile", "could not parse file")
+	}
+
+	if f.Username == "" || f.Password == "" || f.AccessKey == "" {
+		return nil, newError("upsfreight.NewClientFromFile", "file must have a username, password, and accessKey")
+	}
+
+	c := NewClient(f.Username, f.Password, f.AccessKey)
+	if f.Mode != "" {
+		err = c.SetMode(f.Mode)
+		if err != nil {
+			return nil, wrapError(err, "upsfreight.NewClientFromFile", "invalid mode")
+		}
+	}
+
+	return c, nil
+}
+
+//SetCredentials saves the login credentials for the UPS website and API so we can make
+//requests
+//This is safe to call while requests are being made, i.e. to rotate credentials without restarting.
+//Requests already being made finish with the credentials they started with, requests made after this
+//returns use the new credentials.
+func (c *Client) SetCredentials(username, password, accessKey string) {
+	c.credMu.Lock()
+	defer c.credMu.Unlock()
+
+	//web login
+	c.credentials.UsernameToken.Username = username
+	c.credentials.UsernameToken.Password = password
+
+	//api access key
+	c.credentials.UPSServiceAccessToken.AccessLicenseNumber = accessKey
+
+	return
+}
+
+//getCredentials returns a copy of the credentials to use for a request
+func (c *Client) getCredentials() Security {
+	c.credMu.RLock()
+	defer c.credMu.RUnlock()
+
+	return c.credentials
+}
+
+//SetProductionMode chooses the production url for use
+//Pass false to go back to using the test url.
+func (c *Client) SetProductionMode(yes bool) {
+	if yes {
+		c.setURL(upsProductionURL)
+	} else {
+		c.setURL(upsTestURL)
+	}
+
+	return
+}
+
+//SetMode chooses the test or production url from a string, such as from an environment variable
+//Accepted values are "test", "sandbox", "production", and "prod", ignoring case.  An error is returned
+//for any other value and the mode is not changed.
+func (c *Client) SetMode(env string) error {
+	switch strings.ToLower(strings.TrimSpace(env)) {
+	case "test", "sandbox":
+		c.SetProductionMode(false)
+	case "production", "prod":
+		c.SetProductionMode(true)
+	default:
+		return newError("upsfreight.SetMode", "unknown mode "+env+", use test, sandbox, production, or prod")
+	}
+
+	return nil
+}
+
+//SetBaseURL overrides the url requests are sent to
+//Use this to point the client at a local test server, i.e. an httptest.Server, or a proxy.  Calling
+//SetProductionMode or SetMode afterwards replaces this url with the UPS test or production url.
+func (c *Client) SetBaseURL(url string) {
+	c.setURL(url)
+	return
+}
+
+//setURL changes the url requests are sent to
+//This only affects this client, never any other client.
+func (c *Client) setURL(url string) {
+	c.urlMu.Lock()
+	defer c.urlMu.Unlock()
+
+	c.url = url
+	return
+}
+
+//getURL returns the url requests are sent to
+func (c *Client) getURL() string {
+	c.urlMu.RLock()
+	defer c.urlMu.RUnlock()
+
+	return c.url
+}
+
+//BaseURL returns the url requests are sent to
+//Use this to log which UPS environment, or test server, the client is using.
+func (c *Client) BaseURL() string {
+	return c.getURL()
+}
+
+//IsProduction checks if requests are sent to the UPS production url
+//This is false in test mode and when the url was overridden with SetBaseURL.
+func (c *Client) IsProduction() bool {
+	return c.getURL() == upsProductionURL
+}
+
+//SetTimeout updates how long to wait for a reply from UPS
+//use this to increase the timeout if connecting to UPS is really slow
+//Set to 0 to wait for a reply for as long as it takes.
+func (c *Client) SetTimeout(d time.Duration) {
+	c.timeout = d
+	return
+}
+
+//SetOperationTimeout updates how long to wait for a reply from UPS for one type of operation
+//op is the name of the func making the call, i.e. "RequestPickup" or "CancelPickup", the same names
+//given to the metrics func.  Operations without their own timeout use the timeout set with SetTimeout.
+//Set to 0 to go back to using the timeout set with SetTimeout.
+func (c *Client) SetOperationTimeout(op string, d time.Duration) {
+	if d <= 0 {
+		delete(c.operationTimeouts, op)
+		return
+	}
+
+	if c.operationTimeouts == nil {
+		c.operationTimeouts = make(map[string]time.Duration)
+	}
+	c.operationTimeouts[op] = d
+
+	return
+}
+
+//timeoutFor returns how long to wait for a reply from UPS for an operation
+//op may be given with or without the package name, i.e. upsfreight.RequestPickup or RequestPickup.
+func (c *Client) timeoutFor(op string) time.Duration {
+	if d, ok := c.operationTimeouts[strings.TrimPrefix(op, "upsfreight.")]; ok {
+		return d
+	}
+
+	return c.timeout
+}
+
+//protectedHeaders are the headers this package sets itself on every request
+//These cannot be changed with SetHeader since changing them can break every request, use OverrideHeader
+//if you really need to.  Authorization is included so a gateway key isn't set by accident.
+var protectedHeaders = []string{
+	"Accept",
+	"Accept-Encoding",
+	"Authorization",
+	"Content-Length",
+	"Content-Type",
+	"Host",
+	"User-Agent",
+}
+
+//isProtectedHeader checks if a header is one that cannot be set with SetHeader
+func isProtectedHeader(key string) bool {
+	key = http.CanonicalHeaderKey(key)
+	for _, h := range protectedHeaders {
+		if key == h {
+			return true
+		}
+	}
+
+	return false
+}
+
+//SetHeader saves a header that is added to every request sent to UPS
+//Use this for headers required by a corporate proxy or API gateway, i.e. a correlation id.  The headers
+//this package sets itself (Content-Type, Accept, User-Agent, etc.) and Authorization cannot be set with
+//this, an error is returned, use OverrideHeader instead.  Set value to blank to remove the header.
+func (c *Client) SetHeader(key, value string) error {
+	if isProtectedHeader(key) {
+		return newError("upsfreight.SetHeader", http.CanonicalHeaderKey(key)+" cannot be set, use OverrideHeader")
+	}
+
+	if c.headers == nil {
+		c.headers = make(http.Header)
+	}
+
+	if value == "" {
+		c.headers.Del(key)
+	} else {
+		c.headers.Set(key, value)
+	}
+
+	return nil
+}
+
+//OverrideHeader saves a header that replaces, or is added to, the headers on every request sent to UPS
+//Unlike SetHeader this allows changing the headers this package sets itself and Authorization.  Be
+//careful, a mistake here can break every request.  Set value to blank to remove the override.
+func (c *Client) OverrideHeader(key, value string) {
+	if c.headerOverrides == nil {
+		c.headerOverrides = make(http.Header)
+	}
+
+	if value == "" {
+		c.headerOverrides.Del(key)
+	} else {
+		c.headerOverrides.Set(key, value)
+	}
+
+	return
+}
+
+//SetTransport saves the http.RoundTripper used to send requests to UPS
+//Use this to send requests through an instrumented transport (i.e. for tracing), a proxy, or a
+//Cassette to replay recorded responses in tests.  Set to nil to use http.DefaultTransport, the default.
+//This replaces the transport set by SetInsecureTLS.
+func (c *Client) SetTransport(t http.RoundTripper) {
+	c.transport = t
+	return
+}
+
+//SetInsecureTLS turns on or off skipping verification of the server's TLS certificate
+//THIS IS UNSAFE AND ONLY FOR TESTING.  Use this with SetBaseURL to test against a mock server with a
+//self signed certificate.  With this on anyone between you and the server can read and change your
+//requests, including your UPS credentials.  Never turn this on in production.  This is off by default.
+//Turning this off only removes the transport this installed, a transport set with SetTransport is kept.
+func (c *Client) SetInsecureTLS(yes bool) {
+	if !yes {
+		if c.insecureTransport != nil && c.transport == http.RoundTripper(c.insecureTransport) {
+			c.transport = nil
+		}
+		c.insecureTransport = nil
+		return
+	}
+
+	//copy the default transport's settings, i.e. proxies and timeouts, unless it was replaced with
+	//something that isn't an *http.Transport in which case a transport with go's defaults is used
+	var t *http.Transport
+	if dt, ok := http.DefaultTransport.(*http.Transport); ok {
+		t = dt.Clone()
+	} else {
+		t = &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		}
+	}
+
+	t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	c.transport = t
+	c.insecureTransport = t
+
+	return
+}
+
+//SetAuditFunc saves a func that is called after every call to UPS
+//Use this to archive the exact data sent to and received from UPS for compliance purposes.
+//The func is given the request as it was sent, after any BeforeSend hook, the raw response body, and
+//the http status code.  The func is called even when the request fails, in which case req may be nil
+//if the request could not be built, and resp may be nil and status may be 0 if UPS was never reached.
+//Redaction: the password and access key in the request are always replaced with "REDACTED" before
+//the request is given to the func.  The username is left as is so you know which account made the
+//request.  The response is never modified since UPS does not echo credentials back.
+//Set to nil to stop auditing.
+func (c *Client) SetAuditFunc(f func(req, resp []byte, status int)) {
+	c.auditFunc = f
+	return
+}
+
+//SetMetricsFunc saves a func that is called after every call to UPS
+//Use this to collect latency and success/failure counts.  op is the name of the func that was
+//called, i.e. "RequestPickup".  err is nil when the call was successful.
+//Set to nil to stop collecting metrics.
+func (c *Client) SetMetricsFunc(f func(op string, dur time.Duration, err error)) {
+	c.metricsFunc = f
+	return
+}
+
+//SetMinimumNotice updates how far in advance of the start of the pickup window a pickup must be scheduled
+//SetPickupSchedule returns an error if the start time is sooner than this.  This is 0 by default
+//meaning no notice is required.
+func (c *Client) SetMinimumNotice(d time.Duration) {
+	c.minimumNotice = d
+	return
+}
+
+//SetRetryEmptyConfirmation turns on or off retrying a pickup request once when UPS says the request was
+//successful but does not give a confirmation number
+//UPS occasionally responds this way and the same request succeeds when it is sent again.  When on, and
+//only when the response status is successful, the response is not a fault, and the confirmation number
+//is blank, the exact same request, with the same customer context, is sent one more time.  Any other
+//response, including a fault, is never retried, and the retry is never retried.  The duplicate check
+//(see SetDedupeWindow) is not run again for the retry since it is the same pickup, the pickup is only
+//remembered once it has a confirmation number.  UPS does not document whether the first request
+//scheduled a pickup, so if both requests scheduled one there will be two pickups with the same
+//customer context, check with UPS if you see this.  This is off by default.
+func (c *Client) SetRetryEmptyConfirmation(yes bool) {
+	c.retryEmptyConfirmation = yes
+	return
+}
+
+//SetFailOnWarning turns on or off treating warnings from UPS as errors
+//When on, RequestPickup returns a *WarningError if UPS returned any warnings.  The pickup was still
+//scheduled, the response with the confirmation number is returned along with the error, so you need to
+//decide if the pickup should be kept or cancelled.
+func (c *Client) SetFailOnWarning(yes bool) {
+	c.failOnWarning = yes
+	return
+}
+
+//SetMaxResponseSize updates the largest response, in bytes, that will be read from UPS
+//Responses larger than this cause an error instead of being read into memory.
+func (c *Client) SetMaxResponseSize(bytes int64) {
+	c.maxResponseSize = bytes
+	return
+}
+
+//SetWeightLimits updates the minimum and maximum weight, in pounds, Validate allows for a shipment
+//Use this if you legitimately ship heavier loads, near truckload weights, than the default allows.
+//The defaults are 1 and 20,000 pounds.  RequestPickup validates the details so pickups outside these
+//limits are not requested.
+func (c *Client) SetWeightLimits(min, max float64) {
+	c.minWeight = min
+	c.maxWeight = max
+	return
+}
+
+//SetBeforeSend saves a func that can modify the json of each request right before it is sent to UPS
+//This is an escape hatch for when UPS adds a field this package does not support yet.  Be careful, a
+//mistake here can break every request.  The audit func is given the request after this modifies it.
+//Returning an error stops the request from being sent.  Set to nil to remove the hook.
+func (c *Client) SetBeforeSend(f func(body []byte) ([]byte, error)) {
+	c.beforeSend = f
+	return
+}
+
+//SetAfterReceive saves a func that can modify the json of each response from UPS before it is decoded
+//This is an escape hatch for when UPS changes its response in a way this package does not support yet.
+//Be careful, a mistake here can break every response.  The audit func is given the response before
+//this modifies it.  Returning an error fails the request.  Set to nil to remove the hook.
+func (c *Client) SetAfterReceive(f func(body []byte) ([]byte, error)) {
+	c.afterReceive = f
+	return
+}
+
+//SetCheckSameLocation turns on or off rejecting pickups that ship to the same postal code they ship from
+//This is almost always a copy and paste mistake for LTL freight.  When on, Validate returns a
+//*ValidationError for these pickups unless AllowSameLocation is set on the pickup details, for the rare
+//pickup that really is a move within the same postal code.  This is off by default.
+func (c *Client) SetCheckSameLocation(yes bool) {
+	c.checkSameLocation = yes
+	return
+}
+
+//SetStrictDecoding turns on or off rejecting responses from UPS that have unknown fields
+//This is meant for testing against captured UPS responses so changes to the UPS response format are
+//caught and the structs in this package can be kept up to date.  Do not use this in production since
+//UPS may add fields to its responses at any time.
+func (c *Client) SetStrictDecoding(yes bool) {
+	c.strictDecoding = yes
+	return
+}
+
+//SetVerifyCustomerContext turns on or off checking that responses are for the request that was sent
+//UPS echoes the customer context of a request back in its response.  When on, RequestPickup returns an
+//error matching ErrCustomerContextMismatch if the echoed customer context is not the one that was sent,
+//which catches responses being matched to the wrong request.  The response is still returned so you
+//can look into, or cancel, the pickup.  The pickup is not recorded in the pickup store.  This is off by
+//default since UPS does not document that the customer context is always echoed back unchanged.
+func (c *Client) SetVerifyCustomerContext(yes bool) {
+	c.verifyCustomerContext = yes
+	return
+}
+
+//SetPickupStore saves where scheduled pickups should be recorded
+//Once set, each successful RequestPickup is recorded and can be retrieved with ListPickups.
+//Set to nil to stop recording pickups.
+func (c *Client) SetPickupStore(s PickupStore) {
+	c.pickupStore = s
+	return
+}
+
+//SetPickupSchedule sets the date and time range for a pickup
+//This is the time UPS will attempt to perform the pickup
+//Times should be in the future and be on the same date.  The start time must also be at least the
+//minimum notice from now, see SetMinimumNotice, and inside the business hours, see SetBusinessHours and
+//SetNormalizeSchedule.  If the times are not valid a *ScheduleError is returned with a suggested window:
+//the next weekday, at least the minimum notice from now, at the requested times of day, widened to 2
+//hours if needed.
+//UPS only accepts times to the minute so seconds, and anything smaller, are dropped from the times
+//before they are checked, i.e. 10:15:45 is 10:15.  The zero time.Time is never a valid time, it is
+//almost always a time that was never set, so a *ValidationError is returned for it.
+func (c *Client) SetPickupSchedule(prd *PickupRequestDetails, startTime, endTime time.Time) error {
+	const op = "upsfreight.SetPickupSchedule"
+	now := time.Now()
+
+	//make sure the times were set
+	if startTime.IsZero() {
+		return newValidationError(op, "startTime", "is not set, it is the zero time")
+	}
+	if endTime.IsZero() {
+		return newValidationError(op, "endTime", "is not set, it is the zero time")
+	}
+
+	//drop the seconds since UPS only accepts HHMM
+	startTime = startTime.Truncate(time.Minute)
+	endTime = endTime.Truncate(time.Minute)
+
+	//scheduleError builds the error with a suggested window
+	scheduleError := func(message string) error {
+		suggestedStart, suggestedEnd := c.suggestWindow(now, startTime, endTime)
+		return &ScheduleError{
+			Op:             op,
+			Message:        message,
+			SuggestedStart: suggestedStart,
+			SuggestedEnd:   suggestedEnd,
+		}
+	}
+
+	//get date from times and make sure they are the same
+	startYear, startMonth, startDay := startTime.Date()
+	endYear, endMonth, endDay := endTime.Date()
+
+	if (startYear != endYear) || (startMonth != endMonth) || (startDay != endDay) {
+		return scheduleError("startTime and endTime not same date")
+	}
+
+	//make sure the window is inside the business hours, moving it inside them if asked to
+	if !c.isWithinBusinessHours(startTime, endTime) {
+		if !c.normalizeSchedule {
+			return scheduleError("window is outside the business hours")
+		}
+
+		var err error
+		startTime, endTime, err = c.NormalizeSchedule(startTime, endTime)
+		if err != nil {
+			return scheduleError(err.Error())
+		}
+	}
+
+	//make sure start time is in the future
+	if startTime.Sub(now) < 0 {
+		return scheduleError("startTime is in the past")
+	}
+
+	//make sure the pickup is being scheduled far enough in advance
+	if startTime.Sub(now) < c.minimumNotice {
+		return scheduleError("startTime must be at least " + c.minimumNotice.String() + " from now")
+	}
+
+	//make sure end time is after start time
+	//ups also requires a 2 hour window
+	if endTime.Sub(startTime) < minimumPickupWindow {
+		return scheduleError("endTime must be at least 2 hours after start time")
+	}
+
+	//save date and times
+	prd.PickupDate = startTime.Format("20060102")
+	prd.EarliestTimeReady = startTime.Format("1504")
+	prd.LatestTimeReady = endTime.Format("1504")
+	return nil
+}
+
+//RequestPickup performs the call the the UPS API to schedule a pickup
+//The details are validated first, see Validate, and a *ValidationError is returned without calling UPS
+//if they are invalid.  The details are not changed.  Values RequestPickup fills in, i.e. the destination
+//from ShipTo, the origin country, and the client's defaults, are only used for this request.
+func (c *Client) RequestPickup(prd *PickupRequestDetails) (PickupRequestResponse, error) {
+	return c.RequestPickupContext(context.Background(), prd)
+}
+
+//RequestPickupContext is RequestPickup with a context
+//The span for the request, see SetTracer, is started from ctx so it is a child of the caller's span.
+//The call to UPS is cancelled if ctx is cancelled or its deadline passes before the timeout.
+func (c *Client) RequestPickupContext(ctx context.Context, prd *PickupRequestDetails) (responseData PickupRequestResponse, err error) {
+	//record how long the request took and if it was successful
+	start := time.Now()
+	defer func() {
+		c.recordMetrics("RequestPickup", start, err)
+	}()
+
+	//trace the request if needed
+	ctx, span := c.startSpan(ctx, "RequestPickup")
+	defer func() {
+		span.End(err)
+	}()
+
+	//work on a copy so the values filled in below aren't saved to the caller's details, where they would
+	//stick and be used as if they had been set, i.e. if the details are changed and requested again
+	prd = prd.Clone()
+
+	//fill in the client's defaults for anything that wasn't provided
+	c.applyDefaults(prd)
+
+	//make sure the details are valid before bothering UPS
+	err = c.Validate(prd)
+	if err != nil {
+		return
+	}
+
+	//fill in the ship to location from the full ship to address if needed
+	prd.DestinationPostalCode, prd.DestinationCountryCode = prd.destination()
+
+	//fill in the origin country if it wasn't provided
+	//this is only on the copy so a later change to ShipFrom doesn't conflict with a stale origin country
+	if prd.OriginCountryCode == "" {
+		prd.OriginCountryCode = prd.ShipFrom.Address.CountryCode
+	}
+
+	//make sure the request has a unique identifier so the response can be correlated
+	//this is only on the copy so requesting the same details again gets a new identifier
+	if prd.Request.TransactionReference.CustomerContext == "" {
+		prd.GenerateCustomerContext()
+	}
+
+	//make sure this isn't a duplicate of a pickup that was just requested, or is being requested now
+	//the fingerprint is only needed, and built, if duplicates are being checked for
+	//the fingerprint is claimed until UPS responds and released if the pickup wasn't scheduled, a pickup
+	//that was scheduled stays claimed even if an error is returned, i.e. a *WarningError
+	var fingerprint string
+	var scheduled bool
+	checkDuplicate := c.dedupe.enabled()
+	if checkDuplicate {
+		fingerprint = PickupFingerprint(prd)
+
+		if !prd.AllowDuplicate {
+			if !c.dedupe.reserve(fingerprint) {
+				err = wrapError(ErrPossibleDuplicate, "upsfreight.RequestPickup", "")
+				return
+			}
+
+			defer func() {
+				if !scheduled {
+					c.dedupe.release(fingerprint)
+				}
+			}()
+		}
+	}
+
+	//build the PickupRequest struct
+	pickupRequest := c.buildPickupRequest(prd)
+
+	//make the call to UPS
+	//if asked to, the request is sent once more when UPS says it was successful but didn't give a
+	//confirmation number, see SetRetryEmptyConfirmation
+	var (
+		body    []byte
+		transID string
+		fault   *UPSFaultError
+	)
+	for attempt := 1; ; attempt++ {
+		body, transID, err = c.callUPS(ctx, "upsfreight.RequestPickup", pickupRequest)
+		if err != nil {
+			return
+		}
+
+		//decode the response
+		responseData, fault, err = parsePickupResponse(body, c.strictDecoding)
+		if err != nil {
+			err = wrapError(err, "upsfreight.RequestPickup", "could not unmarshal response")
+			return
+		}
+
+		if attempt == 1 && c.retryEmptyConfirmation && isEmptyConfirmation(responseData) {
+			c.logFailure("upsfreight.RequestPickup", "pickup request was successful without a confirmation number, retrying", body)
+			continue
+		}
+		break
+	}
+	responseData.TransactionID = transID
+	responseData.CustomerContext = prd.Request.TransactionReference.CustomerContext
+
+	//check if the request failed and log the response
+	//return UPS's error so we know what to fix
+	if fault != nil {
+		c.logFailure("upsfreight.RequestPickup", "pickup request failed", body)
+		fault.TransactionID = transID
+		err = fault
+		return
+	}
+
+	//pickup request successful
+	//response data will have confirmation number
+	//an email should also have been sent to the requester email
+	scheduled = true
+
+	span.SetAttribute(AttributeConfirmationNumber, responseData.FreightPickupResponse.PickupRequestConfirmationNumber)
+
+	//remember the pickup so a duplicate can be caught
+	//this is done as soon as the pickup is scheduled so it is remembered even if an error is returned below
+	if checkDuplicate {
+		c.dedupe.remember(fingerprint)
+	}
+
+	//make sure the response is for this request
+	sentContext := prd.Request.TransactionReference.CustomerContext
+	echoedContext := responseData.FreightPickupResponse.Response.TransactionReference.CustomerContext
+	if c.verifyCustomerContext && echoedContext != sentContext {
+		err = wrapError(ErrCustomerContextMismatch, "upsfreight.RequestPickup", "sent "+strconv.Quote(sentContext)+", received "+strconv.Quote(echoedContext))
+		return
+	}
+
+	//record the pickup if needed
+	//a failure here is only logged since the pickup was scheduled and returning an error could cause
+	//the pickup to be requested again
+	if c.pickupStore != nil {
+		summary := PickupSummary{
+			ConfirmationNumber: responseData.FreightPickupResponse.PickupRequestConfirmationNumber,
+			CustomerContext:    prd.Request.TransactionReference.CustomerContext,
+			ConsolidationID:    prd.ConsolidationID,
+			RequestedAt:        time.Now(),
+			Details:            *prd,
+		}
+
+		if storeErr := c.pickupStore.Save(summary); storeErr != nil {
+			log.Println("upsfreight.RequestPickup - could not record pickup", storeErr)
+		}
+	}
+
+	//check if UPS warned about anything
+	alerts := responseData.FreightPickupResponse.Response.Alert
+	if c.failOnWarning && len(alerts) > 0 {
+		err = &WarningError{
+			Op:     "upsfreight.RequestPickup",
+			Alerts: alerts,
+		}
+		return
+	}
+
+	return
+}
+
+//buildPickupRequest builds the request sent to UPS from the pickup details
+func (c *Client) buildPickupRequest(prd *PickupRequestDetails) (pickupRequest PickupRequest) {
+	pickupRequest = PickupRequest{
+		Security:             c.getCredentials(),
+		FreightPickupRequest: *prd,
+	}
+
+	//set measure of weight
+	//pounds are used unless a unit was given, Validate made sure a given unit is valid
+	w := &pickupRequest.FreightPickupRequest.ShipmentDetail.Weight
+	if w.UnitOfMeasurement.Code == "" {
+		w.SetUnit(WeightUnitPounds)
+	} else {
+		w.SetUnit(WeightUnit(w.UnitOfMeasurement.Code))
+	}
+
+	return
+}
+
+//redactCredentials replaces any credentials found in data with "REDACTED"
+//This is used on anything we log, in case UPS ever echoes the request back to us, so the password and
+//access key never end up in log files.  The json escaped form of each credential is redacted as well.
+func (c *Client) redactCredentials(data []byte) []byte {
+	credentials := c.getCredentials()
+	secrets := []string{
+		credentials.UsernameToken.Password,
+		credentials.UPSServiceAccessToken.AccessLicenseNumber,
+	}
+
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+
+		data = bytes.Replace(data, []byte(secret), []byte(redacted), -1)
+
+		escaped, _ := json.Marshal(secret)
+		escaped = escaped[1 : len(escaped)-1]
+		data = bytes.Replace(data, escaped, []byte(redacted), -1)
+	}
+
+	return data
+}
+
+//logFailure logs a failed call to UPS with the response UPS sent back
+//Credentials are redacted from the response before it is logged.
+func (c *Client) logFailure(op, message string, body []byte) {
+	log.Println(op + " - " + message)
+	log.Println(string(c.redactCredentials(body)))
+	return
+}
+
+//recordMetrics calls the metrics func, if one is set, with how long an operation took
+//Use this deferred with the time the operation started.
+func (c *Client) recordMetrics(op string, start time.Time, err error) {
+	if c.metricsFunc != nil {
+		c.metricsFunc(op, time.Since(start), err)
+	}
+
+	return
+}
+
+//upsRequest is a request that can be sent to UPS
+//Each request must be able to give us a copy of itself with credentials redacted for auditing, and
+//the credentials it was built with so they can be redacted from the json that was actually sent.
+type upsRequest interface {
+	redactedJSON() ([]byte, error)
+	security() Security
+}
+
+//redactRequest returns the json sent to UPS for a request with the request's credentials redacted
+//The request's own credentials are redacted, not the client's, since SetCredentials may have been
+//called after the request was built.  Without a BeforeSend hook the json sent is the request marshaled
+//so the request is marshaled again with its credentials redacted.  With a hook only the values of the
+//credential fields are replaced in the json the hook returned, everything else is left as it was sent.
+func redactRequest(request upsRequest, sent []byte, hooked bool) []byte {
+	if !hooked {
+		//this can't fail, the same request was just marshaled
+		redactedJSON, _ := request.redactedJSON()
+		return redactedJSON
+	}
+
+	credentials := request.security()
+	sent = redactField(sent, "Password", credentials.UsernameToken.Password)
+	sent = redactField(sent, "AccessLicenseNumber", credentials.UPSServiceAccessToken.AccessLicenseNumber)
+	return sent
+}
+
+//redactField replaces the value of a json field with "REDACTED" where the field has the given value
+//Only the field is matched, not the value on its own, so the same text elsewhere in the json (i.e. in
+//the commodity description) is not redacted.
+func redactField(data []byte, key, value string) []byte {
+	if value == "" {
+		return data
+	}
+
+	encoded, _ := json.Marshal(value)
+	field := regexp.MustCompile(`"` + key + `"\s*:\s*` + regexp.QuoteMeta(string(encoded)))
+	return field.ReplaceAllLiteral(data, []byte(`"`+key+`":"`+redacted+`"`))
+}
+
+//transactionIDHeaders are the response headers UPS may put its transaction id in
+//UPS does not document this header so every name UPS has been seen using is checked, in order.
+var transactionIDHeaders = []string{
+	"X-UPS-TransactionID",
+	"TransId",
+	"X-Transaction-Id",
+	"X-Request-Id",
+}
+
+//transactionID returns the transaction id UPS gave a response, blank if UPS did not give one
+func transactionID(h http.Header) string {
+	for _, name := range transactionIDHeaders {
+		if id := h.Get(name); id != "" {
+			return id
+		}
+	}
+
+	return ""
+}
+
+//callUPS sends a request to UPS and returns the response body and UPS's transaction id for the call
+//This handles the parts common to every call to UPS: auditing, timeouts, and the errors that do not
+//depend on the type of request (maintenance, rate limiting).  op is the name of the func making the
+//call and is used in error messages.  ctx carries the span, if any, from startSpan.
+func (c *Client) callUPS(ctx context.Context, op string, request upsRequest) (body []byte, transID string, err error) {
+	if atomic.LoadInt32(&c.closed) == 1 {
+		err = wrapError(ErrClientClosed, op, "")
+		return
+	}
+
+	//make sure we have credentials before bothering UPS
+	//without these UPS responds with a confusing authentication fault
+	credentials := c.getCredentials()
+	if credentials.UsernameToken.Username == "" || credentials.UsernameToken.Password == "" || credentials.UPSServiceAccessToken.AccessLicenseNumber == "" {
+		err = wrapError(ErrMissingCredentials, op, "")
+		return
+	}
+
+	//audit the request and response if needed
+	//this is deferred so the audit func is called no matter where we return from
+	//the request is audited as it was sent, after the BeforeSend hook modified it, while the raw
+	//response is audited, not the response after the AfterReceive hook modified it
+	var statusCode int
+	var auditBytes, rawBody []byte
+	if c.auditFunc != nil {
+		defer func() {
+			c.auditFunc(auditBytes, rawBody, statusCode)
+		}()
+	}
+
+	//make the call the UPS
+	//set a timeout since golang doesn't set one by default
+	//we don't want this call to hang for too long
+	//a timeout of 0 means no timeout, the same as http.Client
+	if timeout := c.timeoutFor(op); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	//convert the struct to json
+	jsonBytes, err := json.Marshal(request)
+	if err != nil {
+		err = wrapError(err, op, "could not marshal json")
+		return
+	}
+
+	//let the user modify the request if needed
+	if c.beforeSend != nil {
+		jsonBytes, err = c.beforeSend(jsonBytes)
+		if err != nil {
+			err = wrapError(err, op, "BeforeSend hook failed")
+			return
+		}
+	}
+
+	if c.auditFunc != nil {
+		auditBytes = redactRequest(request, jsonBytes, c.beforeSend != nil)
+	}
+
+	//don't call UPS if it has been failing
+	if !c.breaker.allow() {
+		err = wrapError(ErrCircuitOpen, op, "")
+		return
+	}
+
+	//record if UPS could be called successfully for the circuit breaker
+	//this is deferred so the errors found after the response is read (maintenance, rate limiting) count
+	defer func() {
+		c.breaker.record(err != nil)
+	}()
+
+	res, body, err := c.do(ctx, op, http.MethodPost, c.getURL(), jsonBytes)
+	rawBody = body
+	if res != nil {
+		statusCode = res.StatusCode
+		transID = transactionID(res.Header)
+
+		span := spanFromContext(ctx)
+		span.SetAttribute(AttributeHTTPStatusCode, strconv.Itoa(statusCode))
+		if transID != "" {
+			span.SetAttribute(AttributeTransactionID, transID)
+		}
+	}
+	if err != nil {
+		return
+	}
+
+	//let the user modify the response if needed
+	if c.afterReceive != nil {
+		body, err = c.afterReceive(body)
+		if err != nil {
+			err = wrapError(err, op, "AfterReceive hook failed")
+			return
+		}
+	}
+
+	//check if UPS is down for maintenance
+	if isServiceUnavailable(res, body) {
+		err = newServiceUnavailableError(op, res)
+		return
+	}
+
+	//check if we are sending too many requests
+	if res.StatusCode == http.StatusTooManyRequests {
+		err = newRateLimitError(op, res)
+		return
+	}
+
+	return
+}
+
+//maxPooledResponseBuffer is the largest buffer, in bytes, kept in responseBufferPool
+const maxPooledResponseBuffer = 64 << 10
+
+//responseBufferPool holds the buffers responses are read into, see do
+var responseBufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+//do sends an http request to UPS and reads the response
+//This is shared by every call to UPS so each request gets the same headers, timeout (from ctx),
+//decompression, and response size limit no matter the http method.  op is the name of the func making
+//the call and is used in error messages.  The response is returned with its body already read and
+//closed.  The response, and as much of the body as was read, are returned even when the body is too
+//large so they can be audited.
+func (c *Client) do(ctx context.Context, op, method, url string, reqBody []byte) (res *http.Response, body []byte, err error) {
+	httpClient := http.Client{
+		Transport: c.transport,
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(reqBody))
+	if err != nil {
+		err = wrapError(err, op, "could not build "+strings.ToLower(method)+" request")
+		return
+	}
+
+	//add the user's headers first so they can't replace the headers we need
+	for key, values := range c.headers {
+		req.Header[key] = values
+	}
+
+	//set headers explicitly so we aren't relying on defaults
+	//UPS does not use a version header for the freight pickup endpoint so one is not sent
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+
+	//ask for a compressed response to save bandwidth on large responses
+	//setting this ourselves means the response is not decompressed automatically, see below
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	//the user explicitly asked to replace these headers
+	for key, values := range c.headerOverrides {
+		req.Header[key] = values
+	}
+
+	res, err = httpClient.Do(req)
+	if err != nil {
+		err = wrapError(err, op, "could not make "+strings.ToLower(method)+" request")
+		return
+	}
+
+	//read the response
+	defer res.Body.Close()
+
+	//decompress the response if UPS compressed it
+	var reader io.Reader = res.Body
+	if strings.EqualFold(res.Header.Get("Content-Encoding"), "gzip") {
+		gz, gzErr := gzip.NewReader(res.Body)
+		if gzErr != nil {
+			err = wrapError(gzErr, op, "could not decompress response")
+			return
+		}
+		defer gz.Close()
+
+		reader = gz
+	}
+
+	//limit how much is read so an unexpectedly large response can't exhaust memory
+	//this is the decompressed size so a small compressed response can't get around the limit
+	//one extra byte is read so we know if the limit was exceeded
+	//the response is read into a reused buffer and then copied out at its exact size, this saves
+	//growing a new buffer for every response
+	buf := responseBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer func() {
+		//unusually large buffers are not kept so they don't hold on to memory
+		if buf.Cap() <= maxPooledResponseBuffer {
+			responseBufferPool.Put(buf)
+		}
+	}()
+
+	_, err = buf.ReadFrom(io.LimitReader(reader, c.maxResponseSize+1))
+	body = append([]byte(nil), buf.Bytes()...)
+	if err != nil {
+		err = wrapError(err, op, "could not read response")
+		return
+	}
+	if int64(len(body)) > c.maxResponseSize {
+		body = body[:c.maxResponseSize]
+		err = newError(op, "response exceeded the maximum size of "+strconv.FormatInt(c.maxResponseSize, 10)+" bytes")
+		return
+	}
+
+	return
+}
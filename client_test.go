@@ -0,0 +1,930 @@
+package upsfreight
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAuditFuncGetsSentRequest(t *testing.T) {
+	c := newTestClient(t, fixtureHandler(t, "pickup_success.json"))
+
+	var audited []byte
+	c.SetAuditFunc(func(req, resp []byte, status int) {
+		audited = req
+	})
+	c.SetBeforeSend(func(body []byte) ([]byte, error) {
+		return bytes.Replace(body, []byte("please ignore"), []byte("changed by hook"), 1), nil
+	})
+
+	prd := testPickup(t)
+	if _, err := c.RequestPickup(&prd); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Contains(audited, []byte("changed by hook")) {
+		t.Fatalf("expected the audited request to include the BeforeSend changes, got %s", audited)
+	}
+	if bytes.Contains(audited, []byte("testpassword")) || bytes.Contains(audited, []byte("testaccesskey")) {
+		t.Fatalf("expected credentials to be redacted, got %s", audited)
+	}
+	if !bytes.Contains(audited, []byte(redacted)) {
+		t.Fatalf("expected %q in the audited request, got %s", redacted, audited)
+	}
+}
+
+func TestAuditFuncRedactsRequestCredentialsAfterRotation(t *testing.T) {
+	c := newTestClient(t, fixtureHandler(t, "pickup_success.json"))
+
+	var audited []byte
+	c.SetAuditFunc(func(req, resp []byte, status int) {
+		audited = req
+	})
+
+	//rotate the credentials after the request was built with the old ones
+	c.SetBeforeSend(func(body []byte) ([]byte, error) {
+		c.SetCredentials("newuser", "newpassword", "newaccesskey")
+		return body, nil
+	})
+
+	prd := testPickup(t)
+	if _, err := c.RequestPickup(&prd); err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(audited, []byte("testpassword")) || bytes.Contains(audited, []byte("testaccesskey")) {
+		t.Fatalf("expected the credentials the request was sent with to be redacted, got %s", audited)
+	}
+	if bytes.Count(audited, []byte(redacted)) != 2 {
+		t.Fatalf("expected the password and access key to be redacted, got %s", audited)
+	}
+}
+
+func TestAuditFuncOnlyRedactsCredentialFields(t *testing.T) {
+	for _, hooked := range []bool{false, true} {
+		c := newTestClient(t, fixtureHandler(t, "pickup_success.json"))
+		c.SetCredentials("testuser", "parts", "testaccesskey")
+
+		var audited []byte
+		c.SetAuditFunc(func(req, resp []byte, status int) {
+			audited = req
+		})
+		if hooked {
+			c.SetBeforeSend(func(body []byte) ([]byte, error) {
+				return body, nil
+			})
+		}
+
+		prd := testPickup(t)
+		prd.ShipmentDetail.DescriptionOfCommodity = "machine parts"
+		if _, err := c.RequestPickup(&prd); err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Contains(audited, []byte(`"machine parts"`)) {
+			t.Errorf("hook %t: expected the commodity description to be left alone, got %s", hooked, audited)
+		}
+		if !bytes.Contains(audited, []byte(`"Password":"`+redacted+`"`)) {
+			t.Errorf("hook %t: expected the password to be redacted, got %s", hooked, audited)
+		}
+	}
+}
+
+func TestSetInsecureTLSKeepsTransportFromSetTransport(t *testing.T) {
+	c := NewClient("testuser", "testpassword", "testaccesskey")
+
+	c.SetInsecureTLS(true)
+	if c.transport == nil {
+		t.Fatal("expected a transport to be installed")
+	}
+	c.SetInsecureTLS(false)
+	if c.transport != nil {
+		t.Fatalf("expected the installed transport to be removed, got %T", c.transport)
+	}
+
+	custom := &http.Transport{}
+	c.SetTransport(custom)
+	c.SetInsecureTLS(false)
+	if c.transport != http.RoundTripper(custom) {
+		t.Fatal("expected the transport from SetTransport to be kept")
+	}
+
+	c.SetInsecureTLS(true)
+	c.SetTransport(custom)
+	c.SetInsecureTLS(false)
+	if c.transport != http.RoundTripper(custom) {
+		t.Fatal("expected the transport from SetTransport to be kept after replacing the insecure transport")
+	}
+}
+
+func TestSetInsecureTLSReplacedDefaultTransport(t *testing.T) {
+	original := http.DefaultTransport
+	http.DefaultTransport = roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return original.RoundTrip(r)
+	})
+	defer func() {
+		http.DefaultTransport = original
+	}()
+
+	c := NewClient("testuser", "testpassword", "testaccesskey")
+	c.SetInsecureTLS(true)
+
+	tr, ok := c.transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected an *http.Transport, got %T", c.transport)
+	}
+	if tr.TLSClientConfig == nil || !tr.TLSClientConfig.InsecureSkipVerify {
+		t.Fatal("expected certificate verification to be skipped")
+	}
+}
+
+//gzipHandler returns a handler that replies with a gzip compressed file from testdata
+func gzipHandler(t *testing.T, name string) http.HandlerFunc {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write(readFixture(t, name))
+	gz.Close()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			t.Errorf("expected the request to accept gzip, got %q", r.Header.Get("Accept-Encoding"))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(compressed.Bytes())
+	}
+}
+
+func TestGzipResponse(t *testing.T) {
+	c := newTestClient(t, gzipHandler(t, "pickup_success.json"))
+
+	prd := testPickup(t)
+	res, err := c.RequestPickup(&prd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.FreightPickupResponse.PickupRequestConfirmationNumber != "WBU2805291" {
+		t.Fatalf("expected confirmation number WBU2805291, got %q", res.FreightPickupResponse.PickupRequestConfirmationNumber)
+	}
+}
+
+func TestGzipResponseSizeLimit(t *testing.T) {
+	c := newTestClient(t, gzipHandler(t, "pickup_success.json"))
+
+	//the limit applies to the decompressed response, the compressed response is smaller than this
+	c.SetMaxResponseSize(250)
+
+	prd := testPickup(t)
+	if _, err := c.RequestPickup(&prd); err == nil || !strings.Contains(err.Error(), "maximum size") {
+		t.Fatalf("expected the response to exceed the maximum size, got %v", err)
+	}
+}
+
+func TestGzipResponseCorrupt(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(readFixture(t, "pickup_success.json"))
+	})
+
+	prd := testPickup(t)
+	if _, err := c.RequestPickup(&prd); err == nil || !strings.Contains(err.Error(), "decompress") {
+		t.Fatalf("expected a decompression error, got %v", err)
+	}
+}
+
+func TestLoggedResponseRedactsCredentials(t *testing.T) {
+	//the password has characters that are escaped in json so the escaped form must be redacted too
+	const password = `pa"ss\word<&>`
+	const accessKey = "secretaccesskey"
+
+	//respond with a fault that echoes the request back, the worst case for leaking credentials
+	server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		reqBody, _ := ioutil.ReadAll(r.Body)
+
+		var fault PickupRequestError
+		fault.Fault.FaultCode = "Client"
+		fault.Fault.FaultString = string(reqBody)
+		fault.Fault.Detail.Errors.ErrorDetail.PrimaryErrorCode.Code = "10002"
+		json.NewEncoder(w).Encode(fault)
+	})
+
+	c := NewClient("testuser", password, accessKey)
+	c.SetBaseURL(server.BaseURL())
+
+	var logged bytes.Buffer
+	original := log.Writer()
+	log.SetOutput(&logged)
+	defer log.SetOutput(original)
+
+	prd := testPickup(t)
+	if _, err := c.RequestPickup(&prd); err == nil {
+		t.Fatal("expected the fault to be returned as an error")
+	}
+	if _, err := c.CancelPickup("WBU2805291"); err == nil {
+		t.Fatal("expected the fault to be returned as an error")
+	}
+
+	if logged.Len() == 0 {
+		t.Fatal("expected the failures to be logged")
+	}
+
+	escaped, _ := json.Marshal(password)
+	for _, secret := range []string{password, string(escaped[1 : len(escaped)-1]), accessKey} {
+		if strings.Contains(logged.String(), secret) {
+			t.Fatalf("expected %q to be redacted from the log, got %s", secret, logged.String())
+		}
+	}
+	if !strings.Contains(logged.String(), "testuser") {
+		t.Fatalf("expected the request to be logged with the username, got %s", logged.String())
+	}
+}
+
+func TestRequestHeaders(t *testing.T) {
+	var method string
+	var header http.Header
+	success := readFixture(t, "pickup_success.json")
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		header = r.Header
+		w.Write(success)
+	})
+
+	prd := testPickup(t)
+	if _, err := c.RequestPickup(&prd); err != nil {
+		t.Fatal(err)
+	}
+
+	if method != http.MethodPost {
+		t.Errorf("expected a %s request, got %s", http.MethodPost, method)
+	}
+
+	expected := map[string]string{
+		"Content-Type":    "application/json",
+		"Accept":          "application/json",
+		"Accept-Encoding": "gzip",
+		"User-Agent":      "upsfreight/" + Version,
+	}
+	for key, value := range expected {
+		if got := header.Get(key); got != value {
+			t.Errorf("expected %s header %q, got %q", key, value, got)
+		}
+	}
+}
+
+func TestFailOnWarning(t *testing.T) {
+	for _, failOnWarning := range []bool{false, true} {
+		c := newTestClient(t, fixtureHandler(t, "pickup_warning.json"))
+		c.SetFailOnWarning(failOnWarning)
+
+		prd := testPickup(t)
+		res, err := c.RequestPickup(&prd)
+
+		//the pickup was scheduled either way so the response is always returned
+		if res.FreightPickupResponse.PickupRequestConfirmationNumber != "WBU2805291" {
+			t.Errorf("fail on warning %t: expected confirmation number WBU2805291, got %q", failOnWarning, res.FreightPickupResponse.PickupRequestConfirmationNumber)
+		}
+		if len(res.FreightPickupResponse.Response.Alert) != 2 {
+			t.Errorf("fail on warning %t: expected 2 warnings in the response, got %d", failOnWarning, len(res.FreightPickupResponse.Response.Alert))
+		}
+
+		if !failOnWarning {
+			if err != nil {
+				t.Errorf("fail on warning %t: expected no error, got %v", failOnWarning, err)
+			}
+			continue
+		}
+
+		var warningErr *WarningError
+		if !errors.As(err, &warningErr) {
+			t.Fatalf("fail on warning %t: expected a *WarningError, got %v", failOnWarning, err)
+		}
+		if len(warningErr.Alerts) != 2 || warningErr.Alerts[0].Code != "9369301" || warningErr.Alerts[1].Code != "9369302" {
+			t.Errorf("fail on warning %t: expected both warnings in the error, got %+v", failOnWarning, warningErr.Alerts)
+		}
+	}
+}
+
+func TestMissingCredentials(t *testing.T) {
+	called := false
+	server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	tests := []struct {
+		username, password, accessKey string
+	}{
+		{"", "", ""},
+		{"", "testpassword", "testaccesskey"},
+		{"testuser", "", "testaccesskey"},
+		{"testuser", "testpassword", ""},
+	}
+
+	for _, tt := range tests {
+		c := NewClient(tt.username, tt.password, tt.accessKey)
+		c.SetBaseURL(server.BaseURL())
+
+		prd := testPickup(t)
+		if _, err := c.RequestPickup(&prd); !errors.Is(err, ErrMissingCredentials) {
+			t.Errorf("%+v: expected ErrMissingCredentials from RequestPickup, got %v", tt, err)
+		}
+		if _, err := c.CancelPickup("WBU2805291"); !errors.Is(err, ErrMissingCredentials) {
+			t.Errorf("%+v: expected ErrMissingCredentials from CancelPickup, got %v", tt, err)
+		}
+	}
+
+	if called {
+		t.Fatal("expected UPS to not be called without credentials")
+	}
+}
+
+func TestRequestPickupEndToEnd(t *testing.T) {
+	var sent PickupRequest
+	success := readFixture(t, "pickup_success.json")
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&sent); err != nil {
+			t.Errorf("could not decode the request: %v", err)
+		}
+		w.Write(success)
+	})
+
+	prd := testPickup(t)
+	res, err := c.RequestPickup(&prd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.FreightPickupResponse.PickupRequestConfirmationNumber != "WBU2805291" {
+		t.Errorf("expected confirmation number WBU2805291, got %q", res.FreightPickupResponse.PickupRequestConfirmationNumber)
+	}
+	if res.CustomerContext != "upsfreight-selftest" {
+		t.Errorf("expected customer context upsfreight-selftest, got %q", res.CustomerContext)
+	}
+
+	if sent.Security.UsernameToken.Username != "testuser" || sent.Security.UsernameToken.Password != "testpassword" || sent.Security.UPSServiceAccessToken.AccessLicenseNumber != "testaccesskey" {
+		t.Errorf("expected the credentials to be sent, got %+v", sent.Security)
+	}
+
+	details := sent.FreightPickupRequest
+	if details.PickupDate != prd.PickupDate || details.EarliestTimeReady != "1000" || details.LatestTimeReady != "1400" {
+		t.Errorf("expected the schedule to be sent, got %s %s to %s", details.PickupDate, details.EarliestTimeReady, details.LatestTimeReady)
+	}
+	if details.ShipFrom.Address != prd.ShipFrom.Address {
+		t.Errorf("expected the ship from address to be sent, got %+v", details.ShipFrom.Address)
+	}
+	if details.ShipmentDetail.Weight.UnitOfMeasurement.Code != string(WeightUnitPounds) || details.ShipmentDetail.Weight.Value != "500" {
+		t.Errorf("expected 500 pounds to be sent, got %+v", details.ShipmentDetail.Weight)
+	}
+
+	//the unit is only filled in for the request
+	if prd.ShipmentDetail.Weight.UnitOfMeasurement.Code != "" {
+		t.Errorf("expected the details to not be changed, got unit %q", prd.ShipmentDetail.Weight.UnitOfMeasurement.Code)
+	}
+}
+
+func TestRequestPickupFault(t *testing.T) {
+	c := newTestClient(t, fixtureHandler(t, "pickup_fault.json"))
+
+	prd := testPickup(t)
+	_, err := c.RequestPickup(&prd)
+
+	var fault *UPSFaultError
+	if !errors.As(err, &fault) {
+		t.Fatalf("expected a *UPSFaultError, got %v", err)
+	}
+	if fault.Code != "9369002" {
+		t.Errorf("expected code 9369002, got %q", fault.Code)
+	}
+}
+
+func TestRequestPickupInvalidDetails(t *testing.T) {
+	called := false
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	prd := testPickup(t)
+	prd.Requester.Name = ""
+
+	_, err := c.RequestPickup(&prd)
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a *ValidationError, got %v", err)
+	}
+	if called {
+		t.Fatal("expected UPS to not be called with invalid details")
+	}
+}
+
+//urlRecorder returns a transport that records the url of each request and replies with a fixture
+func urlRecorder(t *testing.T, urls *[]string, mu *sync.Mutex) http.RoundTripper {
+	success := readFixture(t, "pickup_success.json")
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		mu.Lock()
+		*urls = append(*urls, r.URL.String())
+		mu.Unlock()
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(bytes.NewReader(success)),
+			Request:    r,
+		}, nil
+	})
+}
+
+func TestClientsInDifferentModes(t *testing.T) {
+	var mu sync.Mutex
+	var testURLs, productionURLs []string
+
+	testClient := NewClient("testuser", "testpassword", "testaccesskey")
+	testClient.SetTransport(urlRecorder(t, &testURLs, &mu))
+	if err := testClient.SetMode("test"); err != nil {
+		t.Fatal(err)
+	}
+
+	productionClient := NewClient("testuser", "testpassword", "testaccesskey")
+	productionClient.SetTransport(urlRecorder(t, &productionURLs, &mu))
+	if err := productionClient.SetMode("production"); err != nil {
+		t.Fatal(err)
+	}
+
+	if testClient.IsProduction() || !productionClient.IsProduction() {
+		t.Fatalf("expected one client in each mode, got %s and %s", testClient.BaseURL(), productionClient.BaseURL())
+	}
+
+	//request pickups from both clients at the same time
+	var wg sync.WaitGroup
+	template := testPickup(t)
+	for _, c := range []*Client{testClient, productionClient} {
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func(c *Client) {
+				defer wg.Done()
+				prd := template.Clone()
+				if _, err := c.RequestPickup(prd); err != nil {
+					t.Error(err)
+				}
+			}(c)
+		}
+	}
+	wg.Wait()
+
+	for _, u := range testURLs {
+		if u != upsTestURL {
+			t.Errorf("expected the test client to use %s, got %s", upsTestURL, u)
+		}
+	}
+	for _, u := range productionURLs {
+		if u != upsProductionURL {
+			t.Errorf("expected the production client to use %s, got %s", upsProductionURL, u)
+		}
+	}
+	if len(testURLs) != 5 || len(productionURLs) != 5 {
+		t.Errorf("expected 5 requests from each client, got %d and %d", len(testURLs), len(productionURLs))
+	}
+
+	//switching one client's mode never changes the other
+	testClient.SetProductionMode(true)
+	productionClient.SetProductionMode(false)
+	if !testClient.IsProduction() || productionClient.IsProduction() {
+		t.Fatal("expected switching modes to only change that client")
+	}
+}
+
+func TestCustomHeaders(t *testing.T) {
+	var header http.Header
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		w.Write([]byte(`{"FreightCancelPickupResponse":{"Response":{"ResponseStatus":{"Code":"1"}}}}`))
+	})
+
+	if err := c.SetHeader("X-Correlation-ID", "abc123"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetHeader("X-Removed", "value"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetHeader("X-Removed", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	//headers this package sets can only be replaced with OverrideHeader
+	for _, key := range []string{"authorization", "Content-Type", "User-Agent"} {
+		if err := c.SetHeader(key, "value"); err == nil {
+			t.Errorf("expected an error setting %s", key)
+		}
+	}
+	c.OverrideHeader("User-Agent", "gateway/1.0")
+	c.OverrideHeader("Authorization", "Bearer token")
+
+	if _, err := c.CancelPickup("WBU2805291"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"X-Correlation-ID": "abc123",
+		"X-Removed":        "",
+		"User-Agent":       "gateway/1.0",
+		"Authorization":    "Bearer token",
+		"Content-Type":     "application/json",
+	}
+	for key, value := range expected {
+		if got := header.Get(key); got != value {
+			t.Errorf("expected %s header %q, got %q", key, value, got)
+		}
+	}
+}
+
+func TestTransactionIDFromResponse(t *testing.T) {
+	c := NewClient("testuser", "testpassword", "testaccesskey")
+	c.SetTransport(httpFixtureTransport(t, "pickup_success.http"))
+
+	prd := testPickup(t)
+	res, err := c.RequestPickup(&prd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.TransactionID != "8a3e1f0c52d64b7f" {
+		t.Errorf("expected transaction id 8a3e1f0c52d64b7f, got %q", res.TransactionID)
+	}
+	if !strings.Contains(res.Summary(), "8a3e1f0c52d64b7f") {
+		t.Errorf("expected the transaction id in the summary, got %s", res.Summary())
+	}
+}
+
+func TestTransactionIDFromFault(t *testing.T) {
+	c := NewClient("testuser", "testpassword", "testaccesskey")
+	c.SetTransport(httpFixtureTransport(t, "pickup_fault.http"))
+
+	prd := testPickup(t)
+	_, err := c.RequestPickup(&prd)
+
+	var fault *UPSFaultError
+	if !errors.As(err, &fault) {
+		t.Fatalf("expected a *UPSFaultError, got %v", err)
+	}
+	if fault.TransactionID != "8a3e1f0c52d64b80" {
+		t.Errorf("expected transaction id 8a3e1f0c52d64b80, got %q", fault.TransactionID)
+	}
+	if !strings.Contains(fault.Summary(), "8a3e1f0c52d64b80") {
+		t.Errorf("expected the transaction id in the summary, got %s", fault.Summary())
+	}
+}
+
+func TestTransactionIDHeaders(t *testing.T) {
+	for _, name := range transactionIDHeaders {
+		h := http.Header{}
+		h.Set(name, "id-"+name)
+		if got := transactionID(h); got != "id-"+name {
+			t.Errorf("expected the id from %s, got %q", name, got)
+		}
+	}
+
+	//the first header found is used
+	h := http.Header{}
+	h.Set("X-Request-Id", "second")
+	h.Set("X-UPS-TransactionID", "first")
+	if got := transactionID(h); got != "first" {
+		t.Errorf("expected the id from X-UPS-TransactionID, got %q", got)
+	}
+
+	if got := transactionID(http.Header{}); got != "" {
+		t.Errorf("expected no id, got %q", got)
+	}
+}
+
+func TestDo(t *testing.T) {
+	var method string
+	var reqBody []byte
+	var header http.Header
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		header = r.Header
+		reqBody, _ = ioutil.ReadAll(r.Body)
+
+		w.Header().Set("X-Request-Id", "abc")
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("response body"))
+	})
+	c.SetHeader("X-Correlation-ID", "123")
+
+	for _, m := range []string{http.MethodPost, http.MethodPut, http.MethodDelete} {
+		res, body, err := c.do(context.Background(), "upsfreight.Test", m, c.BaseURL(), []byte("request body"))
+		if err != nil {
+			t.Fatalf("%s: %v", m, err)
+		}
+
+		if method != m {
+			t.Errorf("expected a %s request, got %s", m, method)
+		}
+		if string(reqBody) != "request body" {
+			t.Errorf("%s: expected the request body to be sent, got %q", m, reqBody)
+		}
+		if header.Get("Content-Type") != "application/json" || header.Get("X-Correlation-ID") != "123" {
+			t.Errorf("%s: expected the headers to be sent, got %v", m, header)
+		}
+
+		if res.StatusCode != http.StatusAccepted || res.Header.Get("X-Request-Id") != "abc" {
+			t.Errorf("%s: expected the response to be returned, got %d %v", m, res.StatusCode, res.Header)
+		}
+		if string(body) != "response body" {
+			t.Errorf("%s: expected the response body to be read, got %q", m, body)
+		}
+	}
+}
+
+func TestDoResponseTooLarge(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("0123456789"))
+	})
+	c.SetMaxResponseSize(4)
+
+	res, body, err := c.do(context.Background(), "upsfreight.Test", http.MethodPost, c.BaseURL(), nil)
+	if err == nil || !strings.Contains(err.Error(), "maximum size") {
+		t.Fatalf("expected the response to exceed the maximum size, got %v", err)
+	}
+
+	//what was read is still returned so it can be audited
+	if res == nil || string(body) != "0123" {
+		t.Fatalf("expected the start of the response, got %q", body)
+	}
+}
+
+func TestDoContextDeadline(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, _, err := c.do(ctx, "upsfreight.Test", http.MethodPost, c.BaseURL(), nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to be exceeded, got %v", err)
+	}
+}
+
+func TestZeroTimeoutMeansNoTimeout(t *testing.T) {
+	c := NewClient("testuser", "testpassword", "testaccesskey")
+	c.SetTimeout(0)
+
+	var deadline bool
+	success := readFixture(t, "pickup_success.json")
+	c.SetTransport(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		_, deadline = r.Context().Deadline()
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(bytes.NewReader(success)),
+			Request:    r,
+		}, nil
+	}))
+
+	prd := testPickup(t)
+	if _, err := c.RequestPickup(&prd); err != nil {
+		t.Fatalf("expected a timeout of 0 to not time out, got %v", err)
+	}
+	if deadline {
+		t.Fatal("expected no deadline with a timeout of 0")
+	}
+
+	//an operation timeout of 0 goes back to the client's timeout, which is none
+	c.SetOperationTimeout("RequestPickup", 0)
+	if _, err := c.RequestPickup(&prd); err != nil || deadline {
+		t.Fatalf("expected no deadline with an operation timeout of 0, got %v", err)
+	}
+}
+
+func TestDoTransportError(t *testing.T) {
+	c := NewClient("testuser", "testpassword", "testaccesskey")
+	c.SetTransport(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	}))
+
+	_, _, err := c.do(context.Background(), "upsfreight.Test", http.MethodPost, c.BaseURL(), nil)
+	if err == nil || !strings.Contains(err.Error(), "upsfreight.Test") || !strings.Contains(err.Error(), "connection refused") {
+		t.Fatalf("expected the error to include the op and the cause, got %v", err)
+	}
+}
+
+func TestVerifyCustomerContext(t *testing.T) {
+	tests := []struct {
+		fixture  string
+		verify   bool
+		mismatch bool
+	}{
+		{"pickup_success.json", true, false},
+		{"pickup_context_mismatch.json", true, true},
+		{"pickup_context_mismatch.json", false, false},
+	}
+
+	for _, tt := range tests {
+		c := newTestClient(t, fixtureHandler(t, tt.fixture))
+		c.SetVerifyCustomerContext(tt.verify)
+
+		prd := testPickup(t)
+		_, err := c.RequestPickup(&prd)
+
+		if tt.mismatch {
+			if !errors.Is(err, ErrCustomerContextMismatch) {
+				t.Errorf("%s verify %t: expected ErrCustomerContextMismatch, got %v", tt.fixture, tt.verify, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s verify %t: expected no error, got %v", tt.fixture, tt.verify, err)
+		}
+	}
+}
+
+func TestVerifyGeneratedCustomerContext(t *testing.T) {
+	//echo the customer context back like UPS does
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var sent PickupRequest
+		json.NewDecoder(r.Body).Decode(&sent)
+
+		var res PickupRequestResponse
+		res.FreightPickupResponse.Response.ResponseStatus.Code = "1"
+		res.FreightPickupResponse.Response.TransactionReference = sent.FreightPickupRequest.Request.TransactionReference
+		res.FreightPickupResponse.PickupRequestConfirmationNumber = "WBU2805291"
+		json.NewEncoder(w).Encode(res)
+	})
+	c.SetVerifyCustomerContext(true)
+
+	prd := testPickup(t)
+	prd.SetCustomerContext("")
+
+	res, err := c.RequestPickup(&prd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.CustomerContext == "" || res.CustomerContext != res.FreightPickupResponse.Response.TransactionReference.CustomerContext {
+		t.Errorf("expected the generated customer context in the response, got %q", res.CustomerContext)
+	}
+	if prd.Request.TransactionReference.CustomerContext != "" {
+		t.Errorf("expected the details to not be changed, got %q", prd.Request.TransactionReference.CustomerContext)
+	}
+}
+
+//stubTransport returns a transport that replies to every request with a fixture without any network
+func stubTransport(b testing.TB, name string) http.RoundTripper {
+	success := readFixture(b, name)
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		ioutil.ReadAll(r.Body)
+		r.Body.Close()
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(bytes.NewReader(success)),
+			Request:    r,
+		}, nil
+	})
+}
+
+func BenchmarkRequestPickup(b *testing.B) {
+	c := NewClient("testuser", "testpassword", "testaccesskey")
+	c.SetTransport(stubTransport(b, "pickup_success.json"))
+
+	//the common case, one commodity with one weight
+	prd := testPickup(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.RequestPickup(&prd); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBuildPickupRequest(b *testing.B) {
+	c := NewClient("testuser", "testpassword", "testaccesskey")
+	prd := testPickup(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(c.buildPickupRequest(&prd)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+//writeCredentialsFile writes a credentials file for NewClientFromFile to a temporary directory
+func writeCredentialsFile(t *testing.T, contents string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestNewClientFromFile(t *testing.T) {
+	tests := []struct {
+		contents   string
+		production bool
+	}{
+		{`{"username": "fileuser", "password": "filepassword", "accessKey": "fileaccesskey"}`, false},
+		{`{"username": "fileuser", "password": "filepassword", "accessKey": "fileaccesskey", "mode": "test"}`, false},
+		{`{"username": "fileuser", "password": "filepassword", "accessKey": "fileaccesskey", "mode": "production"}`, true},
+	}
+
+	for _, tt := range tests {
+		c, err := NewClientFromFile(writeCredentialsFile(t, tt.contents))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.contents, err)
+		}
+
+		credentials := c.getCredentials()
+		if credentials.UsernameToken.Username != "fileuser" || credentials.UsernameToken.Password != "filepassword" || credentials.UPSServiceAccessToken.AccessLicenseNumber != "fileaccesskey" {
+			t.Errorf("%s: expected the credentials from the file, got %+v", tt.contents, credentials)
+		}
+		if c.IsProduction() != tt.production {
+			t.Errorf("%s: expected production %t", tt.contents, tt.production)
+		}
+	}
+}
+
+func TestNewClientFromFileErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{"missing file", filepath.Join(t.TempDir(), "missing.json"), "could not read file"},
+		{"malformed file", writeCredentialsFile(t, `{"username": "fileuser",`), "could not parse file"},
+		{"missing username", writeCredentialsFile(t, `{"password": "filepassword", "accessKey": "fileaccesskey"}`), "must have a username"},
+		{"missing password", writeCredentialsFile(t, `{"username": "fileuser", "accessKey": "fileaccesskey"}`), "must have a username"},
+		{"missing access key", writeCredentialsFile(t, `{"username": "fileuser", "password": "filepassword"}`), "must have a username"},
+		{"unknown mode", writeCredentialsFile(t, `{"username": "fileuser", "password": "filepassword", "accessKey": "fileaccesskey", "mode": "staging"}`), "invalid mode"},
+	}
+
+	for _, tt := range tests {
+		c, err := NewClientFromFile(tt.path)
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.expected, err)
+		}
+		if c != nil {
+			t.Errorf("%s: expected no client", tt.name)
+		}
+	}
+}
+
+//resetDefaultClient lets a test create DefaultClient again, restoring it when the test ends
+func resetDefaultClient(t *testing.T) {
+	saved := DefaultClient
+	t.Cleanup(func() {
+		DefaultClient = saved
+		defaultClientOnce = sync.Once{}
+	})
+
+	DefaultClient = nil
+	defaultClientOnce = sync.Once{}
+}
+
+func TestDefaultClientIsLazy(t *testing.T) {
+	resetDefaultClient(t)
+
+	SetCredentials("defaultuser", "defaultpassword", "defaultaccesskey")
+	if DefaultClient == nil {
+		t.Fatal("expected DefaultClient to be created by the first package level func")
+	}
+	if DefaultClient.getCredentials().UsernameToken.Username != "defaultuser" {
+		t.Fatal("expected the package level func to use DefaultClient")
+	}
+}
+
+func TestDefaultClientReplacedBeforeUse(t *testing.T) {
+	resetDefaultClient(t)
+
+	c := NewClient("testuser", "testpassword", "testaccesskey")
+	DefaultClient = c
+
+	SetProductionMode(true)
+	if DefaultClient != c || !c.IsProduction() {
+		t.Fatal("expected the replacement client to be used as is")
+	}
+}
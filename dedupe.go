@@ -0,0 +1,153 @@
+package upsfreight
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+//ErrPossibleDuplicate is returned when a pickup looks the same as one that was recently requested
+//This is meant to catch double clicks and retries from a UI.  The pickup was not requested.  If the
+//pickup really should be requested again, set AllowDuplicate on the pickup details and request it again.
+var ErrPossibleDuplicate = errors.New("upsfreight - possible duplicate pickup, set AllowDuplicate to request it anyway")
+
+//dedupe remembers the fingerprints of recently requested pickups
+type dedupe struct {
+	mu      sync.Mutex
+	window  time.Duration
+	seen    map[string]time.Time //fingerprint to when the pickup was requested
+	pending map[string]bool      //fingerprints of pickups being requested right now, see reserve
+}
+
+//PickupFingerprint returns an identifier for the parts of a pickup that make it a duplicate of another
+//Two pickups with the same ship from location, pickup date, pickup window, and weight have the same
+//fingerprint.  Use this if you want to check for duplicates against your own database instead of, or
+//in addition to, SetDedupeWindow.
+func PickupFingerprint(prd *PickupRequestDetails) string {
+	a := prd.ShipFrom.Address
+
+	//normalize the weight so 500 and 500.00 are the same
+	weight := strings.TrimSpace(prd.ShipmentDetail.Weight.Value)
+	if w, err := strconv.ParseFloat(weight, 64); err == nil {
+		weight = formatWeight(w)
+	}
+
+	parts := []string{
+		prd.ShipFrom.Name,
+		a.AddressLine,
+		a.City,
+		a.StateProvinceCode,
+		a.PostalCode,
+		a.CountryCode,
+		prd.PickupDate,
+		prd.EarliestTimeReady,
+		prd.LatestTimeReady,
+		weight,
+		prd.ShipmentDetail.Weight.UnitOfMeasurement.Code,
+	}
+	for i, p := range parts {
+		parts[i] = strings.ToUpper(strings.TrimSpace(p))
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(sum[:])
+}
+
+//SetDedupeWindow turns on checking for duplicate pickups
+//A pickup with the same fingerprint, see PickupFingerprint, as a pickup requested within the window,
+//or as a pickup still being requested by another goroutine, returns ErrPossibleDuplicate instead of
+//being requested.  Fingerprints are only kept in memory.
+//Set to 0 to turn off checking for duplicates, this is the default.
+//
+//Deprecated: use Client.SetDedupeWindow, with a client from NewClient, instead.
+func SetDedupeWindow(d time.Duration) {
+	defaultClient().SetDedupeWindow(d)
+	return
+}
+
+//SetDedupeWindow turns on checking for duplicate pickups
+//See the package level SetDedupeWindow for details.
+func (c *Client) SetDedupeWindow(d time.Duration) {
+	c.dedupe.mu.Lock()
+	defer c.dedupe.mu.Unlock()
+
+	c.dedupe.window = d
+	if d <= 0 {
+		c.dedupe.seen = nil
+		c.dedupe.pending = nil
+	}
+
+	return
+}
+
+//enabled checks if duplicates are being checked for
+func (d *dedupe) enabled() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.window > 0
+}
+
+//reserve claims the fingerprint for a pickup that is about to be requested
+//False is returned if a pickup with the fingerprint was requested within the dedupe window, or is being
+//requested right now, meaning this pickup is a duplicate.  Checking and claiming happen together, under
+//the lock, so two identical pickups requested at the same time, i.e. a double click, can't both get
+//through.  The claim must be ended with remember, if the pickup was scheduled, or release.
+//Fingerprints older than the window are removed while checking so the map doesn't grow forever.
+func (d *dedupe) reserve(fingerprint string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.window <= 0 {
+		return true
+	}
+
+	now := time.Now()
+	for f, requestedAt := range d.seen {
+		if now.Sub(requestedAt) > d.window {
+			delete(d.seen, f)
+		}
+	}
+
+	if _, found := d.seen[fingerprint]; found || d.pending[fingerprint] {
+		return false
+	}
+
+	if d.pending == nil {
+		d.pending = make(map[string]bool)
+	}
+	d.pending[fingerprint] = true
+	return true
+}
+
+//release ends the claim on a fingerprint for a pickup that was not scheduled so it can be requested again
+func (d *dedupe) release(fingerprint string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.pending, fingerprint)
+	return
+}
+
+//remember records that a pickup with the fingerprint was requested, ending its claim
+func (d *dedupe) remember(fingerprint string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.pending, fingerprint)
+	if d.window <= 0 {
+		return
+	}
+
+	if d.seen == nil {
+		d.seen = make(map[string]time.Time)
+	}
+	d.seen[fingerprint] = time.Now()
+
+	return
+}
@@ -0,0 +1,103 @@
+package upsfreight
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDedupeRejectsRepeatedPickup(t *testing.T) {
+	c := newTestClient(t, fixtureHandler(t, "pickup_success.json"))
+	c.SetDedupeWindow(time.Hour)
+
+	prd := testPickup(t)
+	if _, err := c.RequestPickup(&prd); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.RequestPickup(&prd); !errors.Is(err, ErrPossibleDuplicate) {
+		t.Fatalf("expected ErrPossibleDuplicate, got %v", err)
+	}
+
+	prd.AllowDuplicate = true
+	if _, err := c.RequestPickup(&prd); err != nil {
+		t.Fatalf("expected AllowDuplicate to skip the check, got %v", err)
+	}
+}
+
+func TestDedupeConcurrentPickups(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	success := readFixture(t, "pickup_success.json")
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		w.Write(success)
+	})
+	c.SetDedupeWindow(time.Hour)
+
+	const n = 5
+	prd := testPickup(t)
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			p := prd
+			_, errs[i] = c.RequestPickup(&p)
+		}(i)
+	}
+
+	//give the goroutines time to reach the server before letting the first request finish
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	var ok, duplicates int
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			ok++
+		case errors.Is(err, ErrPossibleDuplicate):
+			duplicates++
+		default:
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if ok != 1 || duplicates != n-1 {
+		t.Fatalf("expected 1 pickup and %d duplicates, got %d and %d", n-1, ok, duplicates)
+	}
+	if calls != 1 {
+		t.Fatalf("expected UPS to be called once, got %d", calls)
+	}
+}
+
+func TestDedupeReleasedOnFailure(t *testing.T) {
+	fail := true
+	success := readFixture(t, "pickup_success.json")
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write(success)
+	})
+	c.SetDedupeWindow(time.Hour)
+
+	prd := testPickup(t)
+	if _, err := c.RequestPickup(&prd); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	fail = false
+	if _, err := c.RequestPickup(&prd); err != nil {
+		t.Fatalf("expected a failed pickup to not be remembered, got %v", err)
+	}
+}
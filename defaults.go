@@ -0,0 +1,77 @@
+package upsfreight
+
+import "strings"
+
+//SetDefaultShipFrom sets the ship from address used when a pickup's ShipFrom is not set
+//This saves repeating the same address on every pickup if you always ship from one location.  The
+//default is only used when ShipFrom is completely empty, the zero value.  If any field of ShipFrom is
+//set the pickup's ShipFrom is used as is, the default is never merged into it field by field, so a
+//pickup from another location never picks up part of the default address.  RequestPickup fills in
+//the default, on its own copy of the details, before they are validated.
+func (c *Client) SetDefaultShipFrom(sf ShipFromAddress) {
+	c.defaultShipFrom = &sf
+	return
+}
+
+//ClearDefaultShipFrom removes the default ship from address set with SetDefaultShipFrom
+func (c *Client) ClearDefaultShipFrom() {
+	c.defaultShipFrom = nil
+	return
+}
+
+//SetDefaultRequester sets the requester used when a pickup's Requester is not set
+//The default is only used when Requester is completely empty, the zero value, the same as
+//SetDefaultShipFrom.
+func (c *Client) SetDefaultRequester(r Requester) {
+	c.defaultRequester = &r
+	return
+}
+
+//ClearDefaultRequester removes the default requester set with SetDefaultRequester
+func (c *Client) ClearDefaultRequester() {
+	c.defaultRequester = nil
+	return
+}
+
+//SetDefaultCountryCode sets the country code used for addresses that don't have one, i.e. "US"
+//This saves repeating the country on every address if you only ship within one country.  When a pickup
+//is requested the default is used for the ship from address's country, if it is blank, and for the
+//destination country if neither DestinationCountryCode nor ShipTo.Address.CountryCode is set.  A
+//country set on the pickup always wins.  The defaults from SetDefaultShipFrom and SetDefaultRequester
+//are filled in first.  Set to "" to remove the default.
+func (c *Client) SetDefaultCountryCode(code string) error {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code != "" && (len(code) != 2 || strings.Trim(code, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "") {
+		return newValidationError("upsfreight.SetDefaultCountryCode", "code", "must be a two letter country code")
+	}
+
+	c.defaultCountryCode = code
+	return nil
+}
+
+//applyDefaults fills in the client's defaults for the fields of the pickup details that are empty
+//Values set on the pickup details always win over the defaults.
+func (c *Client) applyDefaults(prd *PickupRequestDetails) {
+	if c.defaultShipFrom != nil && prd.ShipFrom == (ShipFromAddress{}) {
+		prd.ShipFrom = *c.defaultShipFrom
+	}
+	if c.defaultRequester != nil && prd.Requester == (Requester{}) {
+		prd.Requester = *c.defaultRequester
+	}
+
+	if c.defaultCountryCode != "" {
+		if prd.ShipFrom.Address.CountryCode == "" {
+			prd.ShipFrom.Address.CountryCode = c.defaultCountryCode
+		}
+
+		//the destination country can be given in either place, only fill in where it was left out
+		if _, countryCode := prd.destination(); countryCode == "" {
+			prd.DestinationCountryCode = c.defaultCountryCode
+			if prd.ShipTo.Address != (Address{}) {
+				prd.ShipTo.Address.CountryCode = c.defaultCountryCode
+			}
+		}
+	}
+
+	return
+}
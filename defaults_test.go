@@ -0,0 +1,97 @@
+package upsfreight
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestDefaultCountryCodeMerge(t *testing.T) {
+	tests := []struct {
+		name                                      string
+		shipFrom, destination, shipTo             string
+		hasShipTo                                 bool
+		wantShipFrom, wantDestination, wantShipTo string
+	}{
+		{"nothing set", "", "", "", false, "US", "US", ""},
+		{"ship from set", "CA", "", "", false, "CA", "US", ""},
+		{"destination set", "", "CA", "", false, "US", "CA", ""},
+		{"ship to set", "", "", "CA", true, "US", "", "CA"},
+		{"ship to without country", "", "", "", true, "US", "US", "US"},
+		{"everything set", "CA", "CA", "CA", true, "CA", "CA", "CA"},
+	}
+
+	c := newClient()
+	if err := c.SetDefaultCountryCode(" us "); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		prd := fixedPickup()
+		prd.ShipFrom.Address.CountryCode = tt.shipFrom
+		prd.DestinationCountryCode = tt.destination
+		if tt.hasShipTo {
+			prd.ShipTo.Address = Address{PostalCode: "30328", CountryCode: tt.shipTo}
+		}
+
+		c.applyDefaults(&prd)
+
+		if prd.ShipFrom.Address.CountryCode != tt.wantShipFrom {
+			t.Errorf("%s: expected ship from country %q, got %q", tt.name, tt.wantShipFrom, prd.ShipFrom.Address.CountryCode)
+		}
+		if prd.DestinationCountryCode != tt.wantDestination {
+			t.Errorf("%s: expected destination country %q, got %q", tt.name, tt.wantDestination, prd.DestinationCountryCode)
+		}
+		if prd.ShipTo.Address.CountryCode != tt.wantShipTo {
+			t.Errorf("%s: expected ship to country %q, got %q", tt.name, tt.wantShipTo, prd.ShipTo.Address.CountryCode)
+		}
+	}
+}
+
+func TestSetDefaultCountryCode(t *testing.T) {
+	c := newClient()
+	for _, code := range []string{"USA", "U", "U1", "us a"} {
+		if err := c.SetDefaultCountryCode(code); err == nil {
+			t.Errorf("%q: expected an error", code)
+		}
+	}
+
+	if err := c.SetDefaultCountryCode("ca"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetDefaultCountryCode(""); err != nil {
+		t.Fatal(err)
+	}
+
+	prd := fixedPickup()
+	prd.ShipFrom.Address.CountryCode = ""
+	c.applyDefaults(&prd)
+	if prd.ShipFrom.Address.CountryCode != "" {
+		t.Errorf("expected the default to be removed, got %q", prd.ShipFrom.Address.CountryCode)
+	}
+}
+
+func TestDefaultCountryCodeOnlyForRequest(t *testing.T) {
+	var sent PickupRequest
+	success := readFixture(t, "pickup_success.json")
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Write(success)
+	})
+	c.SetDefaultCountryCode("US")
+
+	prd := testPickup(t)
+	prd.ShipFrom.Address.CountryCode = ""
+	prd.DestinationCountryCode = ""
+
+	if _, err := c.RequestPickup(&prd); err != nil {
+		t.Fatal(err)
+	}
+
+	if sent.FreightPickupRequest.ShipFrom.Address.CountryCode != "US" || sent.FreightPickupRequest.DestinationCountryCode != "US" {
+		t.Errorf("expected the default country to be sent, got %q and %q", sent.FreightPickupRequest.ShipFrom.Address.CountryCode, sent.FreightPickupRequest.DestinationCountryCode)
+	}
+	if prd.ShipFrom.Address.CountryCode != "" || prd.DestinationCountryCode != "" {
+		t.Errorf("expected the details to not be changed, got %q and %q", prd.ShipFrom.Address.CountryCode, prd.DestinationCountryCode)
+	}
+}
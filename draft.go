@@ -0,0 +1,86 @@
+package upsfreight
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+//storeFormatVersion is the version of the format written by MarshalStore
+//Increase this if the format changes in a way older versions of this package can't read.
+const storeFormatVersion = 1
+
+//storedPickupRequestDetails is the format pickup request details are saved in by MarshalStore
+//The details are saved in the format sent to UPS, plus the fields that are not sent to UPS, so nothing
+//is lost.  Any field added to PickupRequestDetails, or its children, that is not sent to UPS must be
+//added here.
+type storedPickupRequestDetails struct {
+	Version int
+	Details json.RawMessage //the details as they are sent to UPS
+
+	//fields not sent to UPS
+	OriginCountryCode    string
+	AllowDuplicate       bool
+	AllowSameLocation    bool
+	ConsolidationID      string
+	FallbackEMailAddress string
+	ShipTo               ShipToAddress
+	HandlingUnits        int
+	HandlingUnitWeights  []float64
+}
+
+//MarshalStore encodes the pickup request details for saving, i.e. as a draft in your database
+//Unlike json.Marshal, which encodes the details as they are sent to UPS, this keeps every field,
+//including the ones that are never sent to UPS, i.e. ShipTo.  Use UnmarshalStore to read the details back.
+func (prd *PickupRequestDetails) MarshalStore() ([]byte, error) {
+	details, err := json.Marshal(prd)
+	if err != nil {
+		return nil, wrapError(err, "upsfreight.MarshalStore", "could not encode details")
+	}
+
+	stored := storedPickupRequestDetails{
+		Version:              storeFormatVersion,
+		Details:              details,
+		OriginCountryCode:    prd.OriginCountryCode,
+		AllowDuplicate:       prd.AllowDuplicate,
+		AllowSameLocation:    prd.AllowSameLocation,
+		ConsolidationID:      prd.ConsolidationID,
+		FallbackEMailAddress: prd.Requester.FallbackEMailAddress,
+		ShipTo:               prd.ShipTo,
+		HandlingUnits:        prd.ShipmentDetail.HandlingUnits,
+		HandlingUnitWeights:  prd.ShipmentDetail.HandlingUnitWeights,
+	}
+
+	return json.Marshal(stored)
+}
+
+//UnmarshalStore decodes pickup request details saved with MarshalStore
+//Any data already in the details is replaced.
+func (prd *PickupRequestDetails) UnmarshalStore(data []byte) error {
+	var stored storedPickupRequestDetails
+	err := json.Unmarshal(data, &stored)
+	if err != nil {
+		return wrapError(err, "upsfreight.UnmarshalStore", "could not decode data")
+	}
+
+	if stored.Version > storeFormatVersion {
+		return newError("upsfreight.UnmarshalStore", "data was saved by a newer version of this package, format version "+strconv.Itoa(stored.Version))
+	}
+
+	var details PickupRequestDetails
+	err = json.Unmarshal(stored.Details, &details)
+	if err != nil {
+		return wrapError(err, "upsfreight.UnmarshalStore", "could not decode details")
+	}
+
+	details.OriginCountryCode = stored.OriginCountryCode
+	details.AllowDuplicate = stored.AllowDuplicate
+	details.AllowSameLocation = stored.AllowSameLocation
+	details.ConsolidationID = stored.ConsolidationID
+	details.Requester.FallbackEMailAddress = stored.FallbackEMailAddress
+	details.ShipTo = stored.ShipTo
+	details.ShipmentDetail.HandlingUnits = stored.HandlingUnits
+	details.ShipmentDetail.HandlingUnitWeights = stored.HandlingUnitWeights
+
+	*prd = details
+	return nil
+}
@@ -0,0 +1,94 @@
+package upsfreight
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+//fillFields sets every field in v, including nested structs and slices, to a different non zero value
+//n counts the values used so far so each value is different.
+func fillFields(v reflect.Value, n *int) {
+	*n++
+
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			fillFields(v.Field(i), n)
+		}
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 3, 3))
+		for i := 0; i < v.Len(); i++ {
+			fillFields(v.Index(i), n)
+		}
+	case reflect.String:
+		v.SetString("value" + strconv.Itoa(*n))
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int:
+		v.SetInt(int64(*n))
+	case reflect.Float64:
+		v.SetFloat(float64(*n) + 0.5)
+	}
+}
+
+func TestStoreRoundTripKeepsEveryField(t *testing.T) {
+	var prd PickupRequestDetails
+	var n int
+	fillFields(reflect.ValueOf(&prd).Elem(), &n)
+
+	b, err := prd.MarshalStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded PickupRequestDetails
+	if err := decoded.UnmarshalStore(b); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(decoded, prd) {
+		t.Fatalf("expected every field to be kept\ngot:  %+v\nwant: %+v", decoded, prd)
+	}
+}
+
+func TestStoreKeepsEveryNonWireField(t *testing.T) {
+	//fields not sent to UPS are saved by MarshalStore itself, fail if one is added without updating
+	//storedPickupRequestDetails
+	//fields inside a stored struct, i.e. ShipTo, are kept with the struct
+	kept := map[string]bool{
+		"PickupRequestDetails.OriginCountryCode":                  true,
+		"PickupRequestDetails.AllowDuplicate":                     true,
+		"PickupRequestDetails.AllowSameLocation":                  true,
+		"PickupRequestDetails.ConsolidationID":                    true,
+		"PickupRequestDetails.Requester.FallbackEMailAddress":     true,
+		"PickupRequestDetails.ShipTo":                             true,
+		"PickupRequestDetails.ShipmentDetail.HandlingUnits":       true,
+		"PickupRequestDetails.ShipmentDetail.HandlingUnitWeights": true,
+
+		//sent to UPS with AddressLine, see Address.MarshalJSON
+		"PickupRequestDetails.ShipFrom.Address.AddressLine2": true,
+		"PickupRequestDetails.ShipFrom.Address.AddressLine3": true,
+	}
+
+	var check func(path string, typ reflect.Type)
+	check = func(path string, typ reflect.Type) {
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			fieldPath := path + "." + f.Name
+
+			if f.Tag.Get("json") == "-" {
+				if !kept[fieldPath] {
+					t.Errorf("%s is not sent to UPS, add it to storedPickupRequestDetails and this test", fieldPath)
+				}
+				continue
+			}
+
+			if f.Type.Kind() == reflect.Struct {
+				check(fieldPath, f.Type)
+			}
+		}
+	}
+
+	check("PickupRequestDetails", reflect.TypeOf(PickupRequestDetails{}))
+}
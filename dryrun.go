@@ -0,0 +1,37 @@
+package upsfreight
+
+import (
+	"encoding/json"
+	"io"
+)
+
+//WriteRequest writes the json that would be sent to UPS to request the pickup, with the credentials redacted
+//See Client.WriteRequest for details.
+func (prd *PickupRequestDetails) WriteRequest(w io.Writer) error {
+	return defaultClient().WriteRequest(w, prd)
+}
+
+//WriteRequest writes the json that would be sent to UPS to request the pickup, with the credentials redacted
+//Use this to save the request to a file, or compare it with another request, without calling UPS.  The
+//json is encoded directly to w.  The client's defaults (see SetDefaultShipFrom) are filled in and the
+//destination is read from ShipTo if needed, the same as RequestPickup, but the pickup details are not
+//changed and are not validated.  A customer context is only included if one was set, RequestPickup
+//generates one when it is blank.  The password and access key are replaced with "REDACTED" before
+//anything is written.
+func (c *Client) WriteRequest(w io.Writer, prd *PickupRequestDetails) error {
+	//work on a copy so the caller's details are not changed
+	details := *prd
+	c.applyDefaults(&details)
+	details.DestinationPostalCode, details.DestinationCountryCode = details.destination()
+
+	pickupRequest := c.buildPickupRequest(&details)
+	pickupRequest.Security.UsernameToken.Password = redacted
+	pickupRequest.Security.UPSServiceAccessToken.AccessLicenseNumber = redacted
+
+	err := json.NewEncoder(w).Encode(pickupRequest)
+	if err != nil {
+		return wrapError(err, "upsfreight.WriteRequest", "could not write request")
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package upsfreight
+
+//errorCodeDescriptions are readable descriptions of the error codes UPS returns
+//Add codes here as they are found.  The descriptions are written for the person fixing the problem,
+//not copied from UPS, since UPS's own descriptions are often terse or missing.
+var errorCodeDescriptions = map[string]string{
+	//authentication
+	"250001": "Invalid access license for the tool, the access key may not be enabled for the Freight Pickup API",
+	"250002": "Invalid authentication information, check the username, password, and access key",
+	"250003": "Invalid access license number, check the access key",
+	"250004": "Incorrect username or password",
+	"250005": "No access and authentication credentials were provided",
+	"250006": "The maximum number of user access attempts was exceeded, wait before trying again",
+	"250007": "The user id is locked out, unlock it on the UPS website",
+	"250009": "The access license number was not found, check the access key",
+	"250019": "Invalid field value, one of the fields in the request has a value UPS does not accept",
+
+	//request format
+	"10001": "The request is not well formed, the json could not be read by UPS",
+	"10002": "The request is missing a required field or a field is in the wrong format",
+}
+
+//unknownErrorCodeDescription is returned by DescribeErrorCode for codes that are not known
+const unknownErrorCodeDescription = "Unknown UPS error, see the UPS API documentation or contact UPS support"
+
+//DescribeErrorCode returns a readable description of a UPS error code
+//Use this to show a helpful message to your users or support team.  A generic description is returned
+//for codes that are not known.
+func DescribeErrorCode(code string) string {
+	if desc, ok := errorCodeDescriptions[code]; ok {
+		return desc
+	}
+
+	return unknownErrorCodeDescription
+}
@@ -0,0 +1,357 @@
+package upsfreight
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+//ErrServiceUnavailable is matched, using errors.Is, by the *ServiceUnavailableError returned when UPS is
+//down for maintenance
+var ErrServiceUnavailable = errors.New("upsfreight - UPS service unavailable")
+
+//ErrMissingCredentials is returned when a call to UPS is attempted before the username, password, and
+//access key are set with SetCredentials
+var ErrMissingCredentials = errors.New("upsfreight - missing credentials, use SetCredentials")
+
+//ErrAlreadyCancelled is matched, using errors.Is, by the *UPSFaultError returned when cancelling a
+//pickup that was already cancelled
+var ErrAlreadyCancelled = errors.New("upsfreight - pickup already cancelled")
+
+//ErrPickupNotFound is matched, using errors.Is, by the *UPSFaultError returned when UPS does not know
+//of the pickup, i.e. the confirmation number is wrong
+var ErrPickupNotFound = errors.New("upsfreight - pickup not found")
+
+//ErrInvalidAccessKey is matched, using errors.Is, by the *UPSFaultError returned when UPS rejects the
+//access key, i.e. it is wrong or not enabled for the Freight Pickup API
+var ErrInvalidAccessKey = errors.New("upsfreight - access key rejected")
+
+//ErrInvalidLogin is matched, using errors.Is, by the *UPSFaultError returned when UPS rejects the
+//username or password, or the login is locked
+var ErrInvalidLogin = errors.New("upsfreight - username or password rejected")
+
+//ErrCredentialsRejected is matched, using errors.Is, by the *UPSFaultError returned when UPS rejects
+//the credentials for any reason, including the reasons for ErrInvalidAccessKey and ErrInvalidLogin
+var ErrCredentialsRejected = errors.New("upsfreight - credentials rejected")
+
+//ErrCustomerContextMismatch is returned when the customer context UPS echoed back in a response is not
+//the one that was sent, see SetVerifyCustomerContext
+var ErrCustomerContextMismatch = errors.New("upsfreight - response customer context does not match request")
+
+//UPSFaultError is returned when UPS responds with a fault, an error, instead of the expected data
+//Use errors.As to get the UPS error code and description so you know what to fix.
+type UPSFaultError struct {
+	Op          string //the func that made the request, i.e. upsfreight.RequestPickup
+	FaultCode   string
+	FaultString string
+	Severity    string
+	Code        string //the UPS error code
+	Description string //the UPS error message
+
+	TransactionID string //UPS's id for the call, give this to UPS support when asking about the error
+}
+
+//Error implements the error interface
+func (e *UPSFaultError) Error() string {
+	msg := "request failed"
+	if e.Description != "" {
+		msg += ": " + e.Description
+	} else if e.Code != "" {
+		msg += ": " + DescribeErrorCode(e.Code)
+	}
+	if e.Code != "" {
+		msg += " (" + e.Code + ")"
+	}
+
+	return errorMessage(e.Op, msg)
+}
+
+//Summary returns a one line description of the error for logging
+//i.e. pickup FAILED op=upsfreight.RequestPickup code=250002 desc="Invalid Authentication Information" txn=abc123
+//txn is only included if UPS gave a transaction id.
+func (e *UPSFaultError) Summary() string {
+	return "pickup FAILED" +
+		" op=" + summaryValue(e.Op) +
+		" code=" + summaryValue(e.Code) +
+		" desc=" + summaryValue(e.Description) +
+		transactionIDSummary(e.TransactionID)
+}
+
+//Is allows matching this error with errors.Is(err, ErrAlreadyCancelled) or errors.Is(err, ErrPickupNotFound)
+//UPS does not use distinct error codes for these cases so they are matched on UPS's error description.
+//The credential errors, i.e. ErrInvalidAccessKey, are matched on UPS's error code.
+func (e *UPSFaultError) Is(target error) bool {
+	desc := strings.ToLower(e.Description)
+
+	switch target {
+	case ErrAlreadyCancelled:
+		return strings.Contains(desc, "already") && strings.Contains(desc, "cancel")
+	case ErrPickupNotFound:
+		return strings.Contains(desc, "not found") || strings.Contains(desc, "does not exist") || strings.Contains(desc, "invalid pickup request confirmation number")
+	case ErrInvalidAccessKey:
+		return invalidAccessKeyCodes[e.Code]
+	case ErrInvalidLogin:
+		return invalidLoginCodes[e.Code]
+	case ErrCredentialsRejected:
+		return invalidAccessKeyCodes[e.Code] || invalidLoginCodes[e.Code] || credentialsRejectedCodes[e.Code]
+	}
+
+	return false
+}
+
+//UPS error codes for rejected credentials, see errorCodeDescriptions
+var (
+	invalidAccessKeyCodes = map[string]bool{
+		"250001": true,
+		"250003": true,
+		"250009": true,
+	}
+	invalidLoginCodes = map[string]bool{
+		"250004": true,
+		"250006": true,
+		"250007": true,
+	}
+
+	//credentialsRejectedCodes don't say which credential was rejected
+	credentialsRejectedCodes = map[string]bool{
+		"250002": true,
+		"250005": true,
+	}
+)
+
+//newFaultError builds the error returned when UPS responds with a fault
+func newFaultError(op string, errorData PickupRequestError) *UPSFaultError {
+	detail := errorData.Fault.Detail.Errors.ErrorDetail
+	return &UPSFaultError{
+		Op:          op,
+		FaultCode:   errorData.Fault.FaultCode,
+		FaultString: errorData.Fault.FaultString,
+		Severity:    detail.Severity,
+		Code:        detail.PrimaryErrorCode.Code,
+		Description: detail.PrimaryErrorCode.Description,
+	}
+}
+
+//RateLimitError is returned when UPS rejects a request because too many requests were made
+//RetryAfter is how long UPS asked us to wait before trying again, it is 0 if UPS did not say.
+type RateLimitError struct {
+	Op         string
+	RetryAfter time.Duration
+}
+
+//Error implements the error interface
+func (e *RateLimitError) Error() string {
+	msg := "rate limited by UPS"
+	if e.RetryAfter > 0 {
+		msg += ", retry after " + e.RetryAfter.String()
+	}
+
+	return errorMessage(e.Op, msg)
+}
+
+//newRateLimitError builds the error returned when UPS responds with http status 429
+func newRateLimitError(op string, res *http.Response) *RateLimitError {
+	e := &RateLimitError{
+		Op: op,
+	}
+
+	if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil {
+		e.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	return e
+}
+
+//ValidationError is returned when the data provided is missing or invalid
+//Field is the path to the invalid field, i.e. ShipFrom.Address.PostalCode.
+//Validate checks every field, Field and Message are the first invalid field and Fields has every
+//invalid field, including the first.
+type ValidationError struct {
+	Op      string
+	Field   string
+	Message string
+	Fields  []FieldError
+}
+
+//FieldError is one invalid field
+//Field is the path to the field in the pickup request details, i.e. ShipFrom.Address.PostalCode.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+//String returns the field and why it is invalid, i.e. "ShipFrom.Name is required"
+func (f FieldError) String() string {
+	return f.Field + " " + f.Message
+}
+
+//Error implements the error interface
+//Every invalid field is listed when there is more than one.
+func (e *ValidationError) Error() string {
+	if len(e.Fields) < 2 {
+		return errorMessage(e.Op, e.Field+" "+e.Message)
+	}
+
+	fields := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		fields[i] = f.String()
+	}
+	return errorMessage(e.Op, strings.Join(fields, "; "))
+}
+
+//newValidationError builds the error returned when a field is missing or invalid
+func newValidationError(op, field, message string) *ValidationError {
+	return &ValidationError{
+		Op:      op,
+		Field:   field,
+		Message: message,
+	}
+}
+
+//ServiceUnavailableError is returned when UPS is down, usually for scheduled maintenance
+//ResumeAt is when UPS said the service will be back, it is the zero time if UPS did not say.  Use
+//this to back off instead of treating the outage as a failure.
+type ServiceUnavailableError struct {
+	Op       string
+	ResumeAt time.Time
+}
+
+//Error implements the error interface
+func (e *ServiceUnavailableError) Error() string {
+	msg := "UPS service unavailable"
+	if !e.ResumeAt.IsZero() {
+		msg += ", resumes at " + e.ResumeAt.Format(time.RFC3339)
+	}
+
+	return errorMessage(e.Op, msg)
+}
+
+//Is allows matching this error with errors.Is(err, ErrServiceUnavailable)
+func (e *ServiceUnavailableError) Is(target error) bool {
+	return target == ErrServiceUnavailable
+}
+
+//isServiceUnavailable checks if a response from UPS means the service is down for maintenance
+//UPS responds with http status 503 during maintenance windows.  Sometimes the response is instead an
+//html maintenance page, not json, with a 200 status, so we also look for the word "maintenance" in any
+//response that is not json.
+func isServiceUnavailable(res *http.Response, body []byte) bool {
+	if res.StatusCode == http.StatusServiceUnavailable {
+		return true
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] != '{' {
+		return bytes.Contains(bytes.ToLower(trimmed), []byte("maintenance"))
+	}
+
+	return false
+}
+
+//newServiceUnavailableError builds the error returned when UPS is down for maintenance
+//The resume time is read from the Retry-After header which can be a number of seconds or a date.
+func newServiceUnavailableError(op string, res *http.Response) *ServiceUnavailableError {
+	e := &ServiceUnavailableError{
+		Op: op,
+	}
+
+	retryAfter := res.Header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(retryAfter); err == nil {
+		e.ResumeAt = time.Now().Add(time.Duration(seconds) * time.Second)
+	} else if t, err := http.ParseTime(retryAfter); err == nil {
+		e.ResumeAt = t
+	}
+
+	return e
+}
+
+//WarningError is returned when UPS returned warnings and SetFailOnWarning is on
+//The request was still successful, i.e. the pickup was scheduled.
+type WarningError struct {
+	Op     string
+	Alerts Alerts
+}
+
+//Error implements the error interface
+func (e *WarningError) Error() string {
+	msg := "UPS returned warnings:"
+	for _, a := range e.Alerts {
+		msg += " " + a.Description + " (" + a.Code + ");"
+	}
+
+	return errorMessage(e.Op, strings.TrimSuffix(msg, ";"))
+}
+
+//ScheduleError is returned when a pickup schedule is not valid, i.e. it is in the past
+//SuggestedStart and SuggestedEnd are the next window that would be accepted, use them to offer to
+//reschedule.  See SetPickupSchedule for how the suggestion is made.
+type ScheduleError struct {
+	Op             string
+	Message        string
+	SuggestedStart time.Time
+	SuggestedEnd   time.Time
+}
+
+//Error implements the error interface
+func (e *ScheduleError) Error() string {
+	return errorMessage(e.Op, e.Message)
+}
+
+//ErrorPrefix is how the func that returned an error is shown at the start of the error's message
+type ErrorPrefix int32
+
+//error prefix styles
+const (
+	ErrorPrefixOp   ErrorPrefix = iota //the func that returned the error, i.e. "upsfreight.RequestPickup - request failed", the default
+	ErrorPrefixNone                    //no prefix, i.e. "request failed", for when you wrap errors with your own context
+)
+
+//errorPrefix is the style of prefix used for error messages, accessed atomically, see SetErrorPrefix
+var errorPrefix int32 = int32(ErrorPrefixOp)
+
+//SetErrorPrefix sets how the func that returned an error is shown at the start of error messages
+//Every error message is built with this prefix, including the message of the typed errors, i.e.
+//*UPSFaultError.  The func is always available in the Op field of the typed errors.  The sentinel
+//errors, i.e. ErrPickupNotFound, have fixed messages and are not affected.  This applies to every
+//client since errors are also returned by funcs that don't use a client, set it once when your program
+//starts.
+func SetErrorPrefix(p ErrorPrefix) {
+	atomic.StoreInt32(&errorPrefix, int32(p))
+	return
+}
+
+//errorMessage builds an error message with the prefix for the func, op, that returned the error
+//All error messages should be built with this, through newError and wrapError, so the prefix style is
+//consistent.
+func errorMessage(op, message string) string {
+	if ErrorPrefix(atomic.LoadInt32(&errorPrefix)) == ErrorPrefixNone || op == "" {
+		return message
+	}
+
+	return op + " - " + message
+}
+
+//newError builds an error returned by the func op
+func newError(op, message string) error {
+	return errors.New(errorMessage(op, message))
+}
+
+//wrapError adds the func op, and what it was doing, to an error
+//message can be blank to only add the func, i.e. to a sentinel error.  The message is built when the
+//error is wrapped so SetErrorPrefix only affects errors wrapped after it is called.
+func wrapError(err error, op, message string) error {
+	if message == "" {
+		//only the func is added, which is nothing without a prefix
+		if ErrorPrefix(atomic.LoadInt32(&errorPrefix)) == ErrorPrefixNone {
+			return errors.WithStack(err)
+		}
+		return errors.Wrap(err, op)
+	}
+
+	return errors.Wrap(err, errorMessage(op, message))
+}
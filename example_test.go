@@ -0,0 +1,126 @@
+package upsfreight_test
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/coreymgilmore/upsfreight"
+)
+
+func ExamplePickupRequestDetails() {
+	prd := upsfreight.PickupRequestDetails{
+		Request: upsfreight.RequestHeader{
+			TransactionReference: upsfreight.TransactionReference{
+				CustomerContext: "order-10042",
+			},
+		},
+		AdditionalComments:     "call on arrival",
+		PickupInstructions:     "use rear dock",
+		DestinationPostalCode:  "90210",
+		DestinationCountryCode: "US",
+		Requester: upsfreight.Requester{
+			AttentionName:       "Shipping Dept",
+			EMailAddress:        "shipping@example.com",
+			Name:                "Example Co",
+			Phone:               upsfreight.PhoneNum{Number: "5555550100"},
+			ThirdPartyIndicator: "Y",
+		},
+		ShipFrom: upsfreight.ShipFromAddress{
+			AttentionName: "Dock Manager",
+			Name:          "Example Co",
+			Address: upsfreight.Address{
+				AddressLine:       "123 Main St",
+				AddressLine2:      "Suite 200",
+				City:              "Springfield",
+				StateProvinceCode: "IL",
+				PostalCode:        "62701",
+				CountryCode:       "US",
+			},
+			Phone: upsfreight.PhoneNum{Number: "5555550101"},
+		},
+		ShipmentDetail: upsfreight.ShipmentDetail{
+			HazMatIndicator: "Y",
+			PackagingType: upsfreight.PackagingType{
+				Code:        "PLT",
+				Description: "Pallet",
+			},
+			NumberOfPieces:         "2",
+			DescriptionOfCommodity: "machine parts",
+			Weight: upsfreight.Weight{
+				UnitOfMeasurement: upsfreight.UnitOfMeasurement{
+					Code:        "LBS",
+					Description: "Pounds",
+				},
+				Value: "500",
+			},
+		},
+		PickupDate:        "20300115",
+		EarliestTimeReady: "1000",
+		LatestTimeReady:   "1400",
+	}
+
+	j, err := json.MarshalIndent(prd, "", "  ")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(string(j))
+
+	// Output:
+	// {
+	//   "Request": {
+	//     "TransactionReference": {
+	//       "CustomerContext": "order-10042"
+	//     }
+	//   },
+	//   "AdditionalComments": "call on arrival",
+	//   "PickupInstructions": "use rear dock",
+	//   "DestinationPostalCode": "90210",
+	//   "DestinationCountryCode": "US",
+	//   "Requester": {
+	//     "AttentionName": "Shipping Dept",
+	//     "EMailAddress": "shipping@example.com",
+	//     "Name": "Example Co",
+	//     "Phone": {
+	//       "Number": "5555550100"
+	//     },
+	//     "ThirdPartyIndicator": "Y"
+	//   },
+	//   "ShipFrom": {
+	//     "AttentionName": "Dock Manager",
+	//     "Name": "Example Co",
+	//     "Address": {
+	//       "AddressLine": [
+	//         "123 Main St",
+	//         "Suite 200"
+	//       ],
+	//       "City": "Springfield",
+	//       "StateProvinceCode": "IL",
+	//       "PostalCode": "62701",
+	//       "CountryCode": "US"
+	//     },
+	//     "Phone": {
+	//       "Number": "5555550101"
+	//     }
+	//   },
+	//   "ShipmentDetail": {
+	//     "HazMatIndicator": "Y",
+	//     "PackagingType": {
+	//       "Code": "PLT",
+	//       "Description": "Pallet"
+	//     },
+	//     "NumberOfPieces": "2",
+	//     "DescriptionOfCommodity": "machine parts",
+	//     "Weight": {
+	//       "UnitOfMeasurement": {
+	//         "Code": "LBS",
+	//         "Description": "Pounds"
+	//       },
+	//       "Value": "500"
+	//     }
+	//   },
+	//   "PickupDate": "20300115",
+	//   "EarliestTimeReady": "1000",
+	//   "LatestTimeReady": "1400"
+	// }
+}
@@ -0,0 +1,128 @@
+package upsfreight
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+//update rewrites the golden files in testdata instead of comparing against them
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+//testWindow returns a 10am to 2pm pickup window on a weekday a few days from now
+func testWindow() (start, end time.Time) {
+	day := time.Now().AddDate(0, 0, 3)
+	for day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		day = day.AddDate(0, 0, 1)
+	}
+
+	y, m, d := day.Date()
+	start = time.Date(y, m, d, 10, 0, 0, 0, time.Local)
+	end = time.Date(y, m, d, 14, 0, 0, 0, time.Local)
+	return
+}
+
+//testPickup returns valid pickup request details scheduled with testWindow
+func testPickup(t testing.TB) PickupRequestDetails {
+	t.Helper()
+
+	prd := samplePickup()
+	start, end := testWindow()
+	if err := newClient().SetPickupSchedule(&prd, start, end); err != nil {
+		t.Fatal(err)
+	}
+
+	return prd
+}
+
+//readFixture returns the contents of a file in testdata
+func readFixture(t testing.TB, name string) []byte {
+	t.Helper()
+
+	b, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+//newTestClient returns a client that sends its requests to a test server using handler
+//The server is closed when the test finishes.
+func newTestClient(t testing.TB, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	c := NewClient("testuser", "testpassword", "testaccesskey")
+	c.SetBaseURL(srv.URL)
+	return c
+}
+
+//fixtureHandler returns a handler that replies to every request with a file from testdata
+func fixtureHandler(t testing.TB, name string) http.HandlerFunc {
+	body := readFixture(t, name)
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}
+}
+
+//roundTripperFunc lets a func be used as an http.RoundTripper
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+//fixedPickup returns valid pickup request details with a fixed pickup date so the json never changes
+//The date is not checked against the current time so this is only for tests that don't call UPS.
+func fixedPickup() PickupRequestDetails {
+	prd := samplePickup()
+	prd.PickupDate = "20300115"
+	prd.EarliestTimeReady = "1000"
+	prd.LatestTimeReady = "1400"
+	return prd
+}
+
+//checkGolden compares json to a golden file in testdata, rewriting the file if -update is given
+//The json is indented before it is compared so the golden files are readable.
+func checkGolden(t *testing.T, name string, data []byte) {
+	t.Helper()
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, data, "", "  "); err != nil {
+		t.Fatal(err)
+	}
+	indented.WriteByte('\n')
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := ioutil.WriteFile(path, indented.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	expected := readFixture(t, name)
+	if !bytes.Equal(indented.Bytes(), expected) {
+		t.Fatalf("json does not match %s, run go test -update if the change is expected\ngot:\n%s", path, indented.Bytes())
+	}
+}
+
+//httpFixtureTransport returns a transport that replies to every request with a raw http response in
+//testdata, headers and all, i.e. one captured with curl -i
+func httpFixtureTransport(t testing.TB, name string) http.RoundTripper {
+	raw := readFixture(t, name)
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return http.ReadResponse(bufio.NewReader(bytes.NewReader(raw)), r)
+	})
+}
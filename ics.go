@@ -0,0 +1,143 @@
+package upsfreight
+
+import (
+	"bytes"
+	"strings"
+	"time"
+)
+
+//icsTimeFormat is the format of a date and time in an iCalendar file
+const icsTimeFormat = "20060102T150405"
+
+//ICS returns the pickup as an iCalendar (.ics) event that can be added to a calendar
+//prd is the pickup details the pickup was requested with.  The event is for the pickup date and ready
+//window, at the ship from address, with the confirmation number in the summary and description.
+//UPS's pickup times are the local time at the ship from location so the event uses "floating" times,
+//times without a time zone, which calendars show as the same time of day wherever the calendar is.  This
+//is correct when the calendar is in the ship from location's time zone, if it may not be, use ICSIn.
+func (r PickupRequestResponse) ICS(prd *PickupRequestDetails) ([]byte, error) {
+	return r.ics(prd, nil)
+}
+
+//ICSIn returns the pickup as an iCalendar (.ics) event with the times in the ship from location's time zone
+//loc is the time zone of the ship from location, i.e. time.LoadLocation("America/New_York").  The times
+//are converted to UTC in the event so calendars in any time zone show the pickup at the correct time.
+//See ICS for details.
+func (r PickupRequestResponse) ICSIn(prd *PickupRequestDetails, loc *time.Location) ([]byte, error) {
+	if loc == nil {
+		return nil, newError("upsfreight.ICSIn", "location is required")
+	}
+
+	return r.ics(prd, loc)
+}
+
+//ics builds the iCalendar event, loc is nil for floating times
+func (r PickupRequestResponse) ics(prd *PickupRequestDetails, loc *time.Location) ([]byte, error) {
+	const op = "upsfreight.ICS"
+
+	confirmationNumber := r.FreightPickupResponse.PickupRequestConfirmationNumber
+	if confirmationNumber == "" {
+		return nil, newError(op, "response does not have a confirmation number")
+	}
+
+	parseLoc := loc
+	if parseLoc == nil {
+		parseLoc = time.UTC
+	}
+
+	start, err := time.ParseInLocation("200601021504", prd.PickupDate+prd.EarliestTimeReady, parseLoc)
+	if err != nil {
+		return nil, wrapError(err, op, "invalid PickupDate or EarliestTimeReady")
+	}
+	end, err := time.ParseInLocation("200601021504", prd.PickupDate+prd.LatestTimeReady, parseLoc)
+	if err != nil {
+		return nil, wrapError(err, op, "invalid PickupDate or LatestTimeReady")
+	}
+
+	//format the times, floating times don't have the Z that marks UTC
+	formatTime := func(t time.Time) string {
+		if loc == nil {
+			return t.Format(icsTimeFormat)
+		}
+		return t.UTC().Format(icsTimeFormat) + "Z"
+	}
+
+	//build the location from the parts of the ship from address that are set
+	a := prd.ShipFrom.Address
+	var parts []string
+	for _, p := range []string{
+		prd.ShipFrom.Name,
+		strings.Join(a.Lines(), ", "),
+		a.City,
+		strings.TrimSpace(a.StateProvinceCode + " " + a.PostalCode),
+		a.CountryCode,
+	} {
+		if strings.TrimSpace(p) != "" {
+			parts = append(parts, p)
+		}
+	}
+	location := strings.Join(parts, ", ")
+
+	description := "UPS Freight pickup confirmation number " + confirmationNumber + "\n" +
+		"Ready " + receiptTime(prd.EarliestTimeReady) + " to " + receiptTime(prd.LatestTimeReady) + "\n" +
+		"Pieces: " + prd.ShipmentDetail.NumberOfPieces + " " + prd.ShipmentDetail.PackagingType.Description + "\n" +
+		"Weight: " + prd.ShipmentDetail.Weight.Value + " " + prd.ShipmentDetail.Weight.UnitOfMeasurement.Code
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//upsfreight//" + Version + "//EN",
+		"BEGIN:VEVENT",
+		"UID:" + confirmationNumber + "@upsfreight",
+		"DTSTAMP:" + time.Now().UTC().Format(icsTimeFormat) + "Z",
+		"DTSTART:" + formatTime(start),
+		"DTEND:" + formatTime(end),
+		"SUMMARY:" + icsEscape("UPS Freight Pickup "+confirmationNumber),
+		"LOCATION:" + icsEscape(location),
+		"DESCRIPTION:" + icsEscape(description),
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}
+
+	var b bytes.Buffer
+	for _, l := range lines {
+		b.WriteString(icsFold(l))
+		b.WriteString("\r\n")
+	}
+
+	return b.Bytes(), nil
+}
+
+//icsEscape escapes text for use in an iCalendar value
+func icsEscape(s string) string {
+	r := strings.NewReplacer(
+		"\\", "\\\\",
+		";", "\\;",
+		",", "\\,",
+		"\r\n", "\\n",
+		"\n", "\\n",
+	)
+	return r.Replace(s)
+}
+
+//icsFold splits a line longer than the 75 bytes iCalendar allows into multiple lines
+//Continuation lines start with a space.  Lines are only split between characters, never in the middle
+//of a multibyte character.
+func icsFold(line string) string {
+	const maxLength = 75
+
+	var b strings.Builder
+	length := 0
+	for _, r := range line {
+		size := len(string(r))
+		if length+size > maxLength {
+			b.WriteString("\r\n ")
+			length = 1
+		}
+
+		b.WriteRune(r)
+		length += size
+	}
+
+	return b.String()
+}
@@ -0,0 +1,85 @@
+package upsfreight
+
+import (
+	"encoding/json"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+//currencyCode is the format of an ISO 4217 currency code
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+//Money is an amount of money in a currency
+//Use this for any charge or monetary value sent to or received from UPS.  It is marshalled to json in
+//the format UPS uses, the amount as a string with two decimal places and the currency code.
+type Money struct {
+	Amount   float64
+	Currency string //ISO 4217 currency code, i.e. USD
+}
+
+//upsMoney is the json format UPS uses for monetary values
+type upsMoney struct {
+	CurrencyCode  string
+	MonetaryValue string
+}
+
+//String returns the amount with two decimal places and the currency, i.e. 12.34 USD
+func (m Money) String() string {
+	return m.formatAmount() + " " + m.Currency
+}
+
+//formatAmount returns the amount rounded to two decimal places
+func (m Money) formatAmount() string {
+	return strconv.FormatFloat(m.Amount, 'f', 2, 64)
+}
+
+//Validate checks that the amount is a positive, or zero, number and the currency is a valid code
+func (m Money) Validate() error {
+	const op = "upsfreight.Money"
+
+	if math.IsNaN(m.Amount) || math.IsInf(m.Amount, 0) {
+		return newValidationError(op, "Amount", "is not a number")
+	}
+	if m.Amount < 0 {
+		return newValidationError(op, "Amount", "cannot be negative")
+	}
+	if !currencyCode.MatchString(m.Currency) {
+		return newValidationError(op, "Currency", "must be a three letter ISO 4217 currency code, i.e. USD")
+	}
+
+	return nil
+}
+
+//MarshalJSON encodes the money in the format UPS uses
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(upsMoney{
+		CurrencyCode:  m.Currency,
+		MonetaryValue: m.formatAmount(),
+	})
+}
+
+//UnmarshalJSON decodes money from the format UPS uses
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var u upsMoney
+	err := json.Unmarshal(data, &u)
+	if err != nil {
+		return err
+	}
+
+	m.Currency = u.CurrencyCode
+	m.Amount = 0
+
+	value := strings.TrimSpace(u.MonetaryValue)
+	if value == "" {
+		return nil
+	}
+
+	m.Amount, err = strconv.ParseFloat(value, 64)
+	if err != nil {
+		return wrapError(err, "upsfreight.Money", "invalid MonetaryValue")
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package upsfreight
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestMoneyRounding(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		expected string
+	}{
+		{0, "0.00"},
+		{10, "10.00"},
+		{12.3, "12.30"},
+		{12.344, "12.34"},
+		{12.346, "12.35"},
+		{0.1 + 0.2, "0.30"},
+		{0.004, "0.00"},
+		{999999.999, "1000000.00"},
+	}
+
+	for _, tt := range tests {
+		m := Money{Amount: tt.amount, Currency: "USD"}
+
+		if got := m.String(); got != tt.expected+" USD" {
+			t.Errorf("%v: expected %s USD, got %s", tt.amount, tt.expected, got)
+		}
+
+		b, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := `{"CurrencyCode":"USD","MonetaryValue":"` + tt.expected + `"}`; string(b) != expected {
+			t.Errorf("%v: expected %s, got %s", tt.amount, expected, b)
+		}
+	}
+}
+
+func TestMoneyValidate(t *testing.T) {
+	tests := []struct {
+		money Money
+		field string
+	}{
+		{Money{Amount: 12.34, Currency: "USD"}, ""},
+		{Money{Amount: 0, Currency: "CAD"}, ""},
+		{Money{Amount: 12.34, Currency: ""}, "Currency"},
+		{Money{Amount: 12.34, Currency: "usd"}, "Currency"},
+		{Money{Amount: 12.34, Currency: "US"}, "Currency"},
+		{Money{Amount: 12.34, Currency: "USDD"}, "Currency"},
+		{Money{Amount: 12.34, Currency: "U$D"}, "Currency"},
+		{Money{Amount: -0.01, Currency: "USD"}, "Amount"},
+		{Money{Amount: math.NaN(), Currency: "USD"}, "Amount"},
+		{Money{Amount: math.Inf(1), Currency: "USD"}, "Amount"},
+	}
+
+	for _, tt := range tests {
+		err := tt.money.Validate()
+		if tt.field == "" {
+			if err != nil {
+				t.Errorf("%+v: expected no error, got %v", tt.money, err)
+			}
+			continue
+		}
+
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("%+v: expected a *ValidationError, got %v", tt.money, err)
+			continue
+		}
+		if validationErr.Field != tt.field {
+			t.Errorf("%+v: expected the error to be for %s, got %s", tt.money, tt.field, validationErr.Field)
+		}
+	}
+}
+
+func TestMoneyUnmarshalJSON(t *testing.T) {
+	var m Money
+	if err := json.Unmarshal([]byte(`{"CurrencyCode":"USD","MonetaryValue":" 12.34 "}`), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Amount != 12.34 || m.Currency != "USD" {
+		t.Errorf("expected 12.34 USD, got %v", m)
+	}
+
+	if err := json.Unmarshal([]byte(`{"CurrencyCode":"USD","MonetaryValue":"twelve"}`), &m); err == nil {
+		t.Error("expected an error for an invalid MonetaryValue")
+	}
+}
@@ -0,0 +1,68 @@
+package upsfreight
+
+import "strings"
+
+//packagingTypes are the packaging type codes, and matching descriptions, documented by UPS
+//This is the only list of packaging types, NewPackagingType and SupportedPackagingTypes both read from
+//it.  Add new codes here as UPS adds them.
+var packagingTypes = []PackagingType{
+	{Code: "BAG", Description: "Bag"},
+	{Code: "BAL", Description: "Bale"},
+	{Code: "BAR", Description: "Barrel"},
+	{Code: "BDL", Description: "Bundle"},
+	{Code: "BIN", Description: "Bin"},
+	{Code: "BOX", Description: "Box"},
+	{Code: "BSK", Description: "Basket"},
+	{Code: "BUN", Description: "Bunch"},
+	{Code: "CAB", Description: "Cabinet"},
+	{Code: "CAN", Description: "Can"},
+	{Code: "CAR", Description: "Carrier"},
+	{Code: "CAS", Description: "Case"},
+	{Code: "CBY", Description: "Carboy"},
+	{Code: "CON", Description: "Container"},
+	{Code: "CRT", Description: "Crate"},
+	{Code: "CSK", Description: "Cask"},
+	{Code: "CTN", Description: "Carton"},
+	{Code: "CYL", Description: "Cylinder"},
+	{Code: "DRM", Description: "Drum"},
+	{Code: "LOO", Description: "Loose"},
+	{Code: "OTH", Description: "Other"},
+	{Code: "PAL", Description: "Pail"},
+	{Code: "PCS", Description: "Pieces"},
+	{Code: "PKG", Description: "Package"},
+	{Code: "PLN", Description: "Pipe Line"},
+	{Code: "PLT", Description: "Pallet"},
+	{Code: "RCK", Description: "Rack"},
+	{Code: "REL", Description: "Reel"},
+	{Code: "ROL", Description: "Roll"},
+	{Code: "SKD", Description: "Skid"},
+	{Code: "SPL", Description: "Spool"},
+	{Code: "TBE", Description: "Tube"},
+	{Code: "TNK", Description: "Tank"},
+	{Code: "UNT", Description: "Unit"},
+	{Code: "VPK", Description: "Van Pack"},
+	{Code: "WRP", Description: "Wrapped"},
+}
+
+//SupportedPackagingTypes returns the packaging types UPS documents, sorted by code
+//Use this to build a list for a user to choose from.  The list returned is a copy so changing it does
+//not change the packaging types this package knows of.
+func SupportedPackagingTypes() []PackagingType {
+	list := make([]PackagingType, len(packagingTypes))
+	copy(list, packagingTypes)
+	return list
+}
+
+//NewPackagingType returns the packaging type for a code, with the matching description filled in
+//The code is not case sensitive, i.e. skd and SKD both return the Skid packaging type.  An error is
+//returned if UPS does not document the code, see SupportedPackagingTypes.
+func NewPackagingType(code string) (PackagingType, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	for _, p := range packagingTypes {
+		if p.Code == code {
+			return p, nil
+		}
+	}
+
+	return PackagingType{}, newError("upsfreight.NewPackagingType", "unknown packaging type "+code)
+}
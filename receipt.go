@@ -0,0 +1,148 @@
+package upsfreight
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//GetPickupReceipt returns a printable PDF confirming a scheduled pickup
+//UPS does not provide a receipt for a pickup so the PDF is generated locally from the pickup recorded
+//in the pickup store.  The pickup store must be set (see SetPickupStore) and implement PickupFinder, and
+//the pickup must have been requested while the store was set.  The PDF is a single plain text page with
+//the confirmation number, pickup date and window, ship from location, and shipment details.
+func (c *Client) GetPickupReceipt(confirmationNumber string) ([]byte, error) {
+	const op = "upsfreight.GetPickupReceipt"
+
+	finder, ok := c.pickupStore.(PickupFinder)
+	if !ok {
+		return nil, newError(op, "pickup store is not set or cannot look up pickups, see PickupFinder")
+	}
+
+	p, found, err := finder.Find(confirmationNumber)
+	if err != nil {
+		return nil, wrapError(err, op, "could not look up pickup")
+	}
+	if !found {
+		return nil, wrapError(ErrPickupNotFound, op, "")
+	}
+
+	return receiptPDF(receiptLines(p)), nil
+}
+
+//receiptLines returns the text of a pickup receipt, one line per item
+func receiptLines(p PickupSummary) []string {
+	d := p.Details
+	from := d.ShipFrom.Address
+	w := d.ShipmentDetail.Weight
+
+	//show the date and times in a readable format if they are valid
+	date := d.PickupDate
+	if t, err := time.Parse("20060102", d.PickupDate); err == nil {
+		date = t.Format("Monday, January 2, 2006")
+	}
+
+	unit := w.UnitOfMeasurement.Code
+	if unit == "" {
+		unit = string(WeightUnitPounds)
+	}
+
+	return []string{
+		"UPS Freight Pickup Confirmation",
+		"",
+		"Confirmation Number: " + p.ConfirmationNumber,
+		"Pickup Date: " + date,
+		"Pickup Window: " + receiptTime(d.EarliestTimeReady) + " - " + receiptTime(d.LatestTimeReady),
+		"",
+		"Ship From: " + d.ShipFrom.Name,
+		"    " + strings.Join(from.Lines(), ", "),
+		"    " + strings.TrimSpace(from.City+", "+from.StateProvinceCode+" "+from.PostalCode+" "+from.CountryCode),
+		"Destination: " + strings.TrimSpace(d.DestinationPostalCode+" "+d.DestinationCountryCode),
+		"",
+		"Pieces: " + d.ShipmentDetail.NumberOfPieces + " " + d.ShipmentDetail.PackagingType.Description,
+		"Weight: " + w.Value + " " + unit,
+		"Commodity: " + d.ShipmentDetail.DescriptionOfCommodity,
+		"",
+		"Requested By: " + d.Requester.Name + " (" + d.Requester.EMailAddress + ")",
+		"Requested At: " + p.RequestedAt.Format("2006-01-02 15:04 MST"),
+		"Reference: " + p.CustomerContext,
+	}
+}
+
+//receiptTime formats a 24 hour HHMM time as HH:MM, the time is returned as is if it isn't valid
+func receiptTime(hhmm string) string {
+	hour, minute, err := parseHHMM(hhmm)
+	if err != nil {
+		return hhmm
+	}
+
+	return time.Date(0, 1, 1, hour, minute, 0, 0, time.UTC).Format("15:04")
+}
+
+//receiptPDF builds a single page, letter size, PDF with each line of text on its own line
+//This is a minimal PDF using the built in Helvetica font so no fonts need to be embedded.  Characters
+//the font can't show are replaced with question marks.
+func receiptPDF(lines []string) []byte {
+	//build the page content, starting 1 inch from the top left and moving down 16 points per line
+	var content bytes.Buffer
+	content.WriteString("BT\n/F1 12 Tf\n72 720 Td\n16 TL\n")
+	for _, l := range lines {
+		content.WriteString("(" + pdfEscape(l) + ") Tj T*\n")
+	}
+	content.WriteString("ET\n")
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 4 0 R >> >> /Contents 5 0 R >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		"<< /Length " + strconv.Itoa(content.Len()) + " >>\nstream\n" + content.String() + "endstream",
+	}
+
+	//write each object, remembering where it starts for the cross reference table
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, o := range objects {
+		offsets[i] = pdf.Len()
+		pdf.WriteString(strconv.Itoa(i+1) + " 0 obj\n" + o + "\nendobj\n")
+	}
+
+	xref := pdf.Len()
+	pdf.WriteString("xref\n0 " + strconv.Itoa(len(objects)+1) + "\n")
+	pdf.WriteString("0000000000 65535 f \n")
+	for _, offset := range offsets {
+		pdf.WriteString(padOffset(offset) + " 00000 n \n")
+	}
+
+	pdf.WriteString("trailer\n<< /Size " + strconv.Itoa(len(objects)+1) + " /Root 1 0 R >>\n")
+	pdf.WriteString("startxref\n" + strconv.Itoa(xref) + "\n%%EOF\n")
+
+	return pdf.Bytes()
+}
+
+//padOffset formats a byte offset as the 10 digits a PDF cross reference table requires
+func padOffset(offset int) string {
+	s := strconv.Itoa(offset)
+	return strings.Repeat("0", 10-len(s)) + s
+}
+
+//pdfEscape escapes text for use in a PDF string
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case r < ' ' || r > '~':
+			b.WriteRune('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
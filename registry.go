@@ -0,0 +1,150 @@
+package upsfreight
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+//PickupSummary is the record of a pickup that was scheduled successfully
+type PickupSummary struct {
+	ConfirmationNumber string
+	CustomerContext    string
+	ConsolidationID    string               //your id for the orders shipping on the pickup, from the details
+	RequestedAt        time.Time            //when the pickup was requested from UPS
+	Details            PickupRequestDetails //the details the pickup was requested with
+}
+
+//PickupStore is where successfully scheduled pickups are recorded
+//UPS does not provide a way to list the pickups you have scheduled so, if you need this, the pickups
+//must be recorded as they are scheduled.  Implement this interface to record pickups in your own
+//database, or use the MemoryPickupStore.
+type PickupStore interface {
+	//Save records a scheduled pickup
+	Save(p PickupSummary) error
+
+	//List returns the pickups requested between from and to, inclusive
+	List(from, to time.Time) ([]PickupSummary, error)
+}
+
+//PickupFinder is implemented by pickup stores that can look up a pickup by its confirmation number
+//This is optional.  When the pickup store implements this, other funcs, such as CancelPickup, use it
+//to fill in details UPS does not return.
+type PickupFinder interface {
+	//Find returns the pickup with the confirmation number, found is false if the pickup was not recorded
+	Find(confirmationNumber string) (p PickupSummary, found bool, err error)
+}
+
+//PickupRemover is implemented by pickup stores that can remove a pickup that is no longer scheduled
+//This is optional.  When the pickup store implements this, UpdatePickup removes the original pickup
+//so ListPickups only lists the pickup it was replaced with.
+type PickupRemover interface {
+	//Remove removes the pickup with the confirmation number, it is not an error if it was not recorded
+	Remove(confirmationNumber string) error
+}
+
+//SetPickupStore saves where scheduled pickups should be recorded
+//Once set, each successful RequestPickup is recorded and can be retrieved with ListPickups.
+//Set to nil to stop recording pickups.
+//
+//Deprecated: use Client.SetPickupStore, with a client from NewClient, instead.
+func SetPickupStore(s PickupStore) {
+	defaultClient().SetPickupStore(s)
+	return
+}
+
+//ListPickups returns the pickups that were requested between from and to, inclusive
+//UPS does not have an endpoint to list pickups so this reads from the pickup store set with
+//SetPickupStore.  Only pickups requested through this package, while a store was set, are listed.
+//
+//Deprecated: use Client.ListPickups, with a client from NewClient, instead.
+func ListPickups(from, to time.Time) ([]PickupSummary, error) {
+	return defaultClient().ListPickups(from, to)
+}
+
+//ListPickups returns the pickups that were requested between from and to, inclusive
+//See the package level ListPickups for details.
+func (c *Client) ListPickups(from, to time.Time) ([]PickupSummary, error) {
+	if c.pickupStore == nil {
+		return nil, newError("upsfreight.ListPickups", "no pickup store set, UPS does not support listing pickups so use SetPickupStore to record them")
+	}
+
+	pickups, err := c.pickupStore.List(from, to)
+	if err != nil {
+		return nil, wrapError(err, "upsfreight.ListPickups", "could not list pickups")
+	}
+
+	return pickups, nil
+}
+
+//MemoryPickupStore is a PickupStore that keeps pickups in memory
+//Pickups are lost when your program exits so this is mostly useful for testing and short lived
+//programs.  This is safe for concurrent use.
+type MemoryPickupStore struct {
+	mu      sync.Mutex
+	pickups []PickupSummary
+}
+
+//NewMemoryPickupStore returns an empty in memory pickup store
+func NewMemoryPickupStore() *MemoryPickupStore {
+	return &MemoryPickupStore{}
+}
+
+//Save records a scheduled pickup
+func (m *MemoryPickupStore) Save(p PickupSummary) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pickups = append(m.pickups, p)
+	return nil
+}
+
+//List returns the pickups requested between from and to, inclusive, oldest first
+func (m *MemoryPickupStore) List(from, to time.Time) ([]PickupSummary, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := []PickupSummary{}
+	for _, p := range m.pickups {
+		if p.RequestedAt.Before(from) || p.RequestedAt.After(to) {
+			continue
+		}
+
+		list = append(list, p)
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].RequestedAt.Before(list[j].RequestedAt)
+	})
+	return list, nil
+}
+
+//Find returns the pickup with the confirmation number, found is false if the pickup was not recorded
+func (m *MemoryPickupStore) Find(confirmationNumber string) (p PickupSummary, found bool, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, p := range m.pickups {
+		if p.ConfirmationNumber == confirmationNumber {
+			return p, true, nil
+		}
+	}
+
+	return
+}
+
+//Remove removes the pickup with the confirmation number, it is not an error if it was not recorded
+func (m *MemoryPickupStore) Remove(confirmationNumber string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kept := m.pickups[:0]
+	for _, p := range m.pickups {
+		if p.ConfirmationNumber != confirmationNumber {
+			kept = append(kept, p)
+		}
+	}
+	m.pickups = kept
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package upsfreight
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+//sequenceHandler replies to each request with the next body, the last body is repeated
+//The customer context of each request is recorded, in order.
+func sequenceHandler(t *testing.T, bodies ...[]byte) (http.HandlerFunc, func() []string) {
+	var mu sync.Mutex
+	var contexts []string
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var req PickupRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		contexts = append(contexts, req.FreightPickupRequest.Request.TransactionReference.CustomerContext)
+		i := len(contexts) - 1
+		if i >= len(bodies) {
+			i = len(bodies) - 1
+		}
+		w.Write(bodies[i])
+	}
+
+	return handler, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), contexts...)
+	}
+}
+
+//newRetryClient returns a test client that retries empty confirmations, checks for duplicates, and
+//records each request given to the audit func
+func newRetryClient(t *testing.T, handler http.HandlerFunc) (*Client, *[][]byte) {
+	c := newTestClient(t, handler)
+	c.SetRetryEmptyConfirmation(true)
+	c.SetDedupeWindow(time.Minute)
+
+	audited := &[][]byte{}
+	c.SetAuditFunc(func(req, resp []byte, status int) {
+		*audited = append(*audited, req)
+	})
+
+	return c, audited
+}
+
+func TestRetryEmptyConfirmation(t *testing.T) {
+	success := readFixture(t, "pickup_success.json")
+	empty := bytes.Replace(success, []byte("WBU2805291"), nil, 1)
+
+	handler, contexts := sequenceHandler(t, empty, success)
+	c, audited := newRetryClient(t, handler)
+
+	prd := testPickup(t)
+	res, err := c.RequestPickup(&prd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.FreightPickupResponse.PickupRequestConfirmationNumber != "WBU2805291" {
+		t.Fatalf("expected the retry's confirmation number, got %q", res.FreightPickupResponse.PickupRequestConfirmationNumber)
+	}
+
+	//the same request is sent once more
+	sent := contexts()
+	if len(sent) != 2 || sent[0] != sent[1] || sent[0] != res.CustomerContext {
+		t.Fatalf("expected one retry with the same customer context, got %v", sent)
+	}
+	if len(*audited) != 2 || !bytes.Equal((*audited)[0], (*audited)[1]) {
+		t.Fatalf("expected the request and the retry to be audited the same, got %d audits", len(*audited))
+	}
+
+	//the pickup is remembered once it has a confirmation number
+	if _, err := c.RequestPickup(&prd); !errors.Is(err, ErrPossibleDuplicate) {
+		t.Fatalf("expected the pickup to be remembered after the retry, got %v", err)
+	}
+	if len(c.dedupe.pending) != 0 {
+		t.Fatalf("expected no claimed fingerprints, got %v", c.dedupe.pending)
+	}
+}
+
+func TestRetryEmptyConfirmationTwice(t *testing.T) {
+	empty := bytes.Replace(readFixture(t, "pickup_success.json"), []byte("WBU2805291"), nil, 1)
+
+	handler, contexts := sequenceHandler(t, empty)
+	c, audited := newRetryClient(t, handler)
+	store := NewMemoryPickupStore()
+	c.SetPickupStore(store)
+
+	prd := testPickup(t)
+	_, err := c.RequestPickup(&prd)
+
+	var fault *UPSFaultError
+	if !errors.As(err, &fault) {
+		t.Fatalf("expected a *UPSFaultError, got %v", err)
+	}
+	if sent := contexts(); len(sent) != 2 {
+		t.Fatalf("expected the retry to not be retried, got %d requests", len(sent))
+	}
+	if len(*audited) != 2 {
+		t.Fatalf("expected both requests to be audited, got %d audits", len(*audited))
+	}
+	if pickups, _ := store.List(time.Time{}, time.Now()); len(pickups) != 0 {
+		t.Fatalf("expected a pickup without a confirmation number to not be recorded, got %v", pickups)
+	}
+
+	//the pickup is only remembered once it has a confirmation number so it can be requested again
+	if len(c.dedupe.pending) != 0 || len(c.dedupe.seen) != 0 {
+		t.Fatalf("expected the fingerprint to be released, got pending %v, seen %v", c.dedupe.pending, c.dedupe.seen)
+	}
+	if _, err := c.RequestPickup(&prd); errors.Is(err, ErrPossibleDuplicate) {
+		t.Fatal("expected the pickup to not be treated as a duplicate")
+	}
+	if sent := contexts(); len(sent) != 4 {
+		t.Fatalf("expected the pickup to be requested again, with a retry, got %d requests", len(sent))
+	}
+}
+
+func TestRetryEmptyConfirmationOff(t *testing.T) {
+	empty := bytes.Replace(readFixture(t, "pickup_success.json"), []byte("WBU2805291"), nil, 1)
+
+	handler, contexts := sequenceHandler(t, empty)
+	c := newTestClient(t, handler)
+
+	prd := testPickup(t)
+	if _, err := c.RequestPickup(&prd); err == nil {
+		t.Fatal("expected an error for a response without a confirmation number")
+	}
+	if sent := contexts(); len(sent) != 1 {
+		t.Fatalf("expected no retry when off, got %d requests", len(sent))
+	}
+}
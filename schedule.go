@@ -0,0 +1,207 @@
+package upsfreight
+
+import "time"
+
+//minimumPickupWindow is the shortest pickup window UPS accepts
+const minimumPickupWindow = 2 * time.Hour
+
+//CanScheduleToday checks if a pickup can still be scheduled for today
+//cutoff is the terminal's same day pickup cutoff, 24 hour time, HHMM, in now's location.  This is a
+//local check only, UPS is not contacted.  False is returned if the cutoff is not a valid time.
+func CanScheduleToday(now time.Time, cutoff string) bool {
+	hour, minute, err := parseHHMM(cutoff)
+	if err != nil {
+		return false
+	}
+
+	y, m, d := now.Date()
+	cutoffTime := time.Date(y, m, d, hour, minute, 0, 0, now.Location())
+	return now.Before(cutoffTime)
+}
+
+//sameDayLeadTime is how long after now a same day pickup window starts at the earliest
+//This gives UPS time to dispatch a driver for a pickup requested today.
+const sameDayLeadTime = 2 * time.Hour
+
+//SameDayWindow builds a pickup window for today that starts as soon as possible and ends at closeTime
+//closeTime is when the ship from location closes, 24 hour time, HHMM, in now's location.  The window
+//starts 2 hours from now, or the minimum notice from now if that is longer (see SetMinimumNotice),
+//rounded up to the next minute.  An error is returned if the window would be shorter than the 2 hours
+//UPS requires.  Use the returned times with SetPickupSchedule.
+func SameDayWindow(now time.Time, closeTime string) (start, end time.Time, err error) {
+	return defaultClient().SameDayWindow(now, closeTime)
+}
+
+//SameDayWindow builds a pickup window for today that starts as soon as possible and ends at closeTime
+//See the package level SameDayWindow for details.
+func (c *Client) SameDayWindow(now time.Time, closeTime string) (start, end time.Time, err error) {
+	hour, minute, err := parseHHMM(closeTime)
+	if err != nil {
+		err = wrapError(err, "upsfreight.SameDayWindow", "invalid closeTime")
+		return
+	}
+
+	lead := c.minimumNotice
+	if lead < sameDayLeadTime {
+		lead = sameDayLeadTime
+	}
+
+	start = now.Add(lead)
+	if rounded := start.Truncate(time.Minute); !rounded.Equal(start) {
+		start = rounded.Add(time.Minute)
+	}
+
+	y, m, d := now.Date()
+	end = time.Date(y, m, d, hour, minute, 0, 0, now.Location())
+
+	if end.Sub(start) < minimumPickupWindow {
+		err = newError("upsfreight.SameDayWindow", "not enough time left today for a 2 hour pickup window")
+		return
+	}
+
+	return
+}
+
+//defaultSuggestedStartHour is when a suggested pickup window starts if the requested start time can't
+//be used, i.e. a window starting late at night that would end the next day
+const defaultSuggestedStartHour = 8
+
+//suggestWindow finds the next pickup window that SetPickupSchedule would accept
+//The window is moved forward a day at a time, skipping weekends, until it starts at least the minimum
+//notice from now.  The requested times of day are kept, using the start date, and the window is widened
+//to 2 hours if needed.  If business hours are set, see SetBusinessHours, the window is moved and, if
+//needed, shortened to fit inside them.  UPS holidays and terminal hours are not known so UPS may still
+//reject the suggestion.
+func (c *Client) suggestWindow(now, start, end time.Time) (suggestedStart, suggestedEnd time.Time) {
+	y, m, d := start.Date()
+	loc := start.Location()
+
+	//keep the requested times of day on the start date
+	length := time.Date(y, m, d, end.Hour(), end.Minute(), 0, 0, loc).Sub(start)
+	if length < minimumPickupWindow {
+		length = minimumPickupWindow
+	}
+
+	//a window that would end on the next day starts in the morning instead
+	startHour, startMinute := start.Hour(), start.Minute()
+	if s := time.Date(y, m, d, startHour, startMinute, 0, 0, loc); s.Add(length).Day() != s.Day() {
+		startHour, startMinute = defaultSuggestedStartHour, 0
+	}
+
+	//keep the window inside the business hours
+	//business hours are always at least 2 hours long so the window is never shorter than UPS requires
+	startAt := time.Duration(startHour)*time.Hour + time.Duration(startMinute)*time.Minute
+	if c.hasBusinessHours {
+		if length > c.businessClose-c.businessOpen {
+			length = c.businessClose - c.businessOpen
+		}
+		if startAt < c.businessOpen {
+			startAt = c.businessOpen
+		}
+		if startAt+length > c.businessClose {
+			startAt = c.businessClose - length
+		}
+	}
+
+	earliest := now.Add(c.minimumNotice)
+	for day := 0; ; day++ {
+		suggestedStart = time.Date(y, m, d+day, int(startAt/time.Hour), int(startAt%time.Hour/time.Minute), 0, 0, loc)
+
+		weekday := suggestedStart.Weekday()
+		if weekday == time.Saturday || weekday == time.Sunday {
+			continue
+		}
+		if suggestedStart.Before(earliest) {
+			continue
+		}
+
+		suggestedEnd = suggestedStart.Add(length)
+		return
+	}
+}
+
+//SetBusinessHours saves the hours pickups can be made at the ship from location, 24 hour time, HHMM
+//Once set, SetPickupSchedule returns a *ScheduleError for a window outside these hours unless
+//SetNormalizeSchedule is turned on, in which case the window is moved inside these hours.
+func (c *Client) SetBusinessHours(open, close string) error {
+	openHour, openMinute, err := parseHHMM(open)
+	if err != nil {
+		return wrapError(err, "upsfreight.SetBusinessHours", "invalid open time")
+	}
+	closeHour, closeMinute, err := parseHHMM(close)
+	if err != nil {
+		return wrapError(err, "upsfreight.SetBusinessHours", "invalid close time")
+	}
+
+	openAt := time.Duration(openHour)*time.Hour + time.Duration(openMinute)*time.Minute
+	closeAt := time.Duration(closeHour)*time.Hour + time.Duration(closeMinute)*time.Minute
+	if closeAt-openAt < minimumPickupWindow {
+		return newError("upsfreight.SetBusinessHours", "business hours must be at least 2 hours long")
+	}
+
+	c.businessOpen = openAt
+	c.businessClose = closeAt
+	c.hasBusinessHours = true
+	return nil
+}
+
+//SetNormalizeSchedule turns on or off moving pickup windows inside the business hours
+//When on, SetPickupSchedule adjusts the window with NormalizeSchedule instead of returning an error.
+//This is off by default.  SetBusinessHours must be called for this to have any effect.
+func (c *Client) SetNormalizeSchedule(yes bool) {
+	c.normalizeSchedule = yes
+	return
+}
+
+//NormalizeSchedule moves a pickup window inside the business hours, on the same date
+//The start is moved no earlier than opening and the end no later than closing.  If that leaves less
+//than the 2 hours UPS requires, the window is widened, first by moving the end later and then by moving
+//the start earlier, without going outside the business hours.  The adjusted window is returned so you
+//can show it to the user.  An error is returned if business hours are not set, see SetBusinessHours, or
+//the window is entirely outside the business hours.
+func (c *Client) NormalizeSchedule(startTime, endTime time.Time) (start, end time.Time, err error) {
+	if !c.hasBusinessHours {
+		err = newError("upsfreight.NormalizeSchedule", "business hours are not set, use SetBusinessHours")
+		return
+	}
+
+	y, m, d := startTime.Date()
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, startTime.Location())
+	opening := midnight.Add(c.businessOpen)
+	closing := midnight.Add(c.businessClose)
+
+	if !startTime.Before(closing) || !endTime.After(opening) {
+		err = newError("upsfreight.NormalizeSchedule", "window is outside the business hours")
+		return
+	}
+
+	start, end = startTime, endTime
+	if start.Before(opening) {
+		start = opening
+	}
+	if end.After(closing) {
+		end = closing
+	}
+
+	//widen the window to the minimum, staying inside the business hours
+	if end.Sub(start) < minimumPickupWindow {
+		end = start.Add(minimumPickupWindow)
+		if end.After(closing) {
+			end = closing
+			start = end.Add(-minimumPickupWindow)
+		}
+	}
+
+	return
+}
+
+//isWithinBusinessHours checks if a window is inside the business hours, true if the hours are not set
+func (c *Client) isWithinBusinessHours(startTime, endTime time.Time) bool {
+	if !c.hasBusinessHours {
+		return true
+	}
+
+	y, m, d := startTime.Date()
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, startTime.Location())
+	return !startTime.Before(midnight.Add(c.businessOpen)) && !endTime.After(midnight.Add(c.businessClose))
+}
@@ -0,0 +1,240 @@
+package upsfreight
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSuggestWindowInsideBusinessHours(t *testing.T) {
+	c := newClient()
+	if err := c.SetBusinessHours("0800", "1700"); err != nil {
+		t.Fatal(err)
+	}
+
+	start, _ := testWindow()
+	y, m, d := start.Date()
+	at := func(hour, minute int) time.Time {
+		return time.Date(y, m, d, hour, minute, 0, 0, time.Local)
+	}
+	now := at(0, 0).AddDate(0, 0, -7)
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+	}{
+		{"before opening", at(5, 0), at(7, 0)},
+		{"after closing", at(18, 0), at(20, 0)},
+		{"ends after closing", at(16, 0), at(18, 0)},
+		{"longer than business hours", at(6, 0), at(20, 0)},
+		{"late at night", at(23, 0), at(23, 30)},
+	}
+
+	for _, tt := range tests {
+		suggestedStart, suggestedEnd := c.suggestWindow(now, tt.start, tt.end)
+		if !c.isWithinBusinessHours(suggestedStart, suggestedEnd) {
+			t.Errorf("%s: suggested %s to %s is outside the business hours", tt.name, suggestedStart.Format("15:04"), suggestedEnd.Format("15:04"))
+		}
+		if suggestedEnd.Sub(suggestedStart) < minimumPickupWindow {
+			t.Errorf("%s: suggested window is shorter than 2 hours", tt.name)
+		}
+	}
+}
+
+func TestSetPickupScheduleSuggestionIsAccepted(t *testing.T) {
+	c := newClient()
+	if err := c.SetBusinessHours("0900", "1500"); err != nil {
+		t.Fatal(err)
+	}
+
+	start, _ := testWindow()
+	y, m, d := start.Date()
+
+	var prd PickupRequestDetails
+	err := c.SetPickupSchedule(&prd, time.Date(y, m, d, 16, 0, 0, 0, time.Local), time.Date(y, m, d, 18, 0, 0, 0, time.Local))
+
+	var scheduleErr *ScheduleError
+	if !errors.As(err, &scheduleErr) {
+		t.Fatalf("expected a *ScheduleError, got %v", err)
+	}
+
+	if err := c.SetPickupSchedule(&prd, scheduleErr.SuggestedStart, scheduleErr.SuggestedEnd); err != nil {
+		t.Fatalf("expected the suggested window to be accepted, got %v", err)
+	}
+}
+
+func TestSetPickupSchedule(t *testing.T) {
+	start, _ := testWindow()
+	y, m, d := start.Date()
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(y, m, d+day, hour, minute, 0, 0, time.Local)
+	}
+	past := time.Now().Add(-3 * time.Hour)
+
+	tests := []struct {
+		name       string
+		setup      func(c *Client)
+		start, end time.Time
+		valid      bool
+	}{
+		{"valid", nil, at(0, 10, 0), at(0, 14, 0), true},
+		{"exactly 2 hours", nil, at(0, 10, 0), at(0, 12, 0), true},
+		{"zero start", nil, time.Time{}, at(0, 14, 0), false},
+		{"zero end", nil, at(0, 10, 0), time.Time{}, false},
+		{"different dates", nil, at(0, 10, 0), at(1, 14, 0), false},
+		{"in the past", nil, past, past.Add(2 * time.Hour), false},
+		{"less than 2 hours", nil, at(0, 10, 0), at(0, 11, 59), false},
+		{"end before start", nil, at(0, 14, 0), at(0, 10, 0), false},
+		{"not enough notice", func(c *Client) { c.SetMinimumNotice(24 * 365 * time.Hour) }, at(0, 10, 0), at(0, 14, 0), false},
+		{"outside business hours", func(c *Client) { c.SetBusinessHours("1200", "1700") }, at(0, 10, 0), at(0, 14, 0), false},
+		{"normalized into business hours", func(c *Client) {
+			c.SetBusinessHours("1200", "1700")
+			c.SetNormalizeSchedule(true)
+		}, at(0, 10, 0), at(0, 14, 0), true},
+	}
+
+	for _, tt := range tests {
+		c := newClient()
+		if tt.setup != nil {
+			tt.setup(c)
+		}
+
+		var prd PickupRequestDetails
+		err := c.SetPickupSchedule(&prd, tt.start, tt.end)
+		if tt.valid && err != nil {
+			t.Errorf("%s: expected no error, got %v", tt.name, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+		if !tt.valid && prd.PickupDate != "" {
+			t.Errorf("%s: expected the schedule to not be saved", tt.name)
+		}
+	}
+}
+
+func TestSetPickupScheduleSavesTimes(t *testing.T) {
+	start, _ := testWindow()
+	y, m, d := start.Date()
+
+	var prd PickupRequestDetails
+	err := newClient().SetPickupSchedule(&prd, time.Date(y, m, d, 9, 5, 0, 0, time.Local), time.Date(y, m, d, 16, 30, 0, 0, time.Local))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := start.Format("20060102"); prd.PickupDate != expected {
+		t.Errorf("expected pickup date %s, got %s", expected, prd.PickupDate)
+	}
+	if prd.EarliestTimeReady != "0905" || prd.LatestTimeReady != "1630" {
+		t.Errorf("expected 0905 to 1630, got %s to %s", prd.EarliestTimeReady, prd.LatestTimeReady)
+	}
+}
+
+func TestSetPickupScheduleZeroTimes(t *testing.T) {
+	start, end := testWindow()
+
+	tests := []struct {
+		start, end time.Time
+		field      string
+	}{
+		{time.Time{}, end, "startTime"},
+		{start, time.Time{}, "endTime"},
+		{time.Time{}, time.Time{}, "startTime"},
+	}
+
+	for _, tt := range tests {
+		var prd PickupRequestDetails
+		err := newClient().SetPickupSchedule(&prd, tt.start, tt.end)
+
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("%v to %v: expected a *ValidationError, got %v", tt.start, tt.end, err)
+			continue
+		}
+		if validationErr.Field != tt.field {
+			t.Errorf("%v to %v: expected the error to be for %s, got %s", tt.start, tt.end, tt.field, validationErr.Field)
+		}
+	}
+}
+
+func TestSetPickupScheduleSubMinute(t *testing.T) {
+	start, _ := testWindow()
+	y, m, d := start.Date()
+
+	tests := []struct {
+		start, end       time.Time
+		earliest, latest string
+	}{
+		//seconds and nanoseconds are dropped, never rounded up
+		{time.Date(y, m, d, 10, 0, 59, 999999999, time.Local), time.Date(y, m, d, 14, 0, 0, 1, time.Local), "1000", "1400"},
+		{time.Date(y, m, d, 10, 29, 30, 0, time.Local), time.Date(y, m, d, 14, 59, 59, 0, time.Local), "1029", "1459"},
+
+		//the window is measured after the seconds are dropped, so this is exactly 2 hours
+		{time.Date(y, m, d, 10, 0, 45, 0, time.Local), time.Date(y, m, d, 12, 0, 15, 0, time.Local), "1000", "1200"},
+	}
+
+	for _, tt := range tests {
+		var prd PickupRequestDetails
+		if err := newClient().SetPickupSchedule(&prd, tt.start, tt.end); err != nil {
+			t.Errorf("%v to %v: %v", tt.start, tt.end, err)
+			continue
+		}
+
+		if prd.EarliestTimeReady != tt.earliest || prd.LatestTimeReady != tt.latest {
+			t.Errorf("%v to %v: expected %s to %s, got %s to %s", tt.start, tt.end, tt.earliest, tt.latest, prd.EarliestTimeReady, prd.LatestTimeReady)
+		}
+	}
+
+	//a window a few seconds short of 2 hours is still too short once the seconds are dropped
+	var prd PickupRequestDetails
+	err := newClient().SetPickupSchedule(&prd, time.Date(y, m, d, 10, 0, 30, 0, time.Local), time.Date(y, m, d, 11, 59, 59, 0, time.Local))
+	if err == nil {
+		t.Error("expected an error for a window shorter than 2 hours")
+	}
+}
+
+func TestSameDayWindow(t *testing.T) {
+	tests := []struct {
+		now           string
+		closeTime     string
+		minimumNotice time.Duration
+		start         string
+		valid         bool
+	}{
+		{"10:00:00", "1700", 0, "12:00", true},
+		{"10:00:30", "1700", 0, "12:01", true},
+		{"10:00:00", "1700", time.Hour, "12:00", true},
+		{"10:00:00", "1700", 3 * time.Hour, "13:00", true},
+		{"10:00:00", "1400", 0, "12:00", true},
+		{"10:00:01", "1400", 0, "", false},
+		{"15:30:00", "1700", 0, "", false},
+	}
+
+	for _, tt := range tests {
+		c := newClient()
+		c.SetMinimumNotice(tt.minimumNotice)
+
+		clock, _ := time.Parse("15:04:05", tt.now)
+		now := time.Date(2030, 1, 15, clock.Hour(), clock.Minute(), clock.Second(), 0, time.Local)
+
+		start, end, err := c.SameDayWindow(now, tt.closeTime)
+		if !tt.valid {
+			if err == nil {
+				t.Errorf("%s to %s: expected an error, got %s to %s", tt.now, tt.closeTime, start, end)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s to %s: %v", tt.now, tt.closeTime, err)
+			continue
+		}
+
+		if got := start.Format("15:04:05"); got != tt.start+":00" {
+			t.Errorf("%s to %s, notice %s: expected the window to start at %s, got %s", tt.now, tt.closeTime, tt.minimumNotice, tt.start, got)
+		}
+		if got := end.Format("1504"); got != tt.closeTime || !end.After(now) {
+			t.Errorf("%s to %s: expected the window to end at the close, got %s", tt.now, tt.closeTime, end)
+		}
+	}
+}
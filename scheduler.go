@@ -0,0 +1,17 @@
+package upsfreight
+
+//PickupScheduler is the pickup operations this package supports, implemented by *Client
+//Use this to program against an interface instead of the client directly, i.e. to treat UPS Freight as
+//one of several carriers or to substitute a fake in your tests.  Methods may be added to this
+//interface as this package supports more operations, so embed it, or wrap a *Client, in your own
+//implementations instead of implementing it from scratch.
+type PickupScheduler interface {
+	//RequestPickup schedules a pickup, see Client.RequestPickup
+	RequestPickup(prd *PickupRequestDetails) (PickupRequestResponse, error)
+
+	//CancelPickup cancels a previously scheduled pickup, see Client.CancelPickup
+	CancelPickup(confirmationNumber string) (CancelPickupResult, error)
+}
+
+//make sure the client implements the interface
+var _ PickupScheduler = (*Client)(nil)
@@ -0,0 +1,188 @@
+package upsfreight
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+//SelfTestReport is the outcome of each step of a SelfTest
+type SelfTestReport struct {
+	Steps []SelfTestStep
+}
+
+//SelfTestStep is the outcome of one step of a SelfTest
+//Err is nil if the step was successful.
+type SelfTestStep struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+}
+
+//Passed returns true if every step of the self test was successful
+func (r SelfTestReport) Passed() bool {
+	for _, s := range r.Steps {
+		if s.Err != nil {
+			return false
+		}
+	}
+
+	return len(r.Steps) > 0
+}
+
+//run performs one step of a self test and records the outcome
+func (r *SelfTestReport) run(name string, f func() error) error {
+	start := time.Now()
+	err := f()
+
+	r.Steps = append(r.Steps, SelfTestStep{
+		Name:     name,
+		Duration: time.Since(start),
+		Err:      err,
+	})
+	return err
+}
+
+//SelfTest checks that your credentials work and pickups can be requested and cancelled
+//A sample pickup is requested against the UPS test url and then immediately cancelled.  This only
+//works in test mode, an error is returned in production mode so a real truck is never dispatched.
+//Use this when setting up a new UPS account to make sure everything works end to end.  The report
+//shows the outcome of each step, steps after a failed step are not run.
+//
+//Deprecated: use Client.SelfTest, with a client from NewClient, instead.
+func SelfTest() (SelfTestReport, error) {
+	return defaultClient().SelfTest()
+}
+
+//SelfTest checks that your credentials work and pickups can be requested and cancelled
+//See the package level SelfTest for details.
+func (c *Client) SelfTest() (report SelfTestReport, err error) {
+	if c.IsProduction() {
+		err = newError("upsfreight.SelfTest", "refusing to run in production mode")
+		return
+	}
+
+	//build a sample pickup
+	//the sample is for tomorrow, or monday if tomorrow is the weekend, during business hours
+	var prd PickupRequestDetails
+	err = report.run("build sample pickup", func() error {
+		prd = samplePickup()
+
+		day := time.Now().AddDate(0, 0, 1)
+		for day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			day = day.AddDate(0, 0, 1)
+		}
+
+		y, m, d := day.Date()
+		start := time.Date(y, m, d, 10, 0, 0, 0, time.Local)
+		end := time.Date(y, m, d, 14, 0, 0, 0, time.Local)
+		return c.SetPickupSchedule(&prd, start, end)
+	})
+	if err != nil {
+		return
+	}
+
+	//request the pickup
+	var confirmationNumber string
+	err = report.run("request pickup", func() error {
+		res, err := c.RequestPickup(&prd)
+		confirmationNumber = res.FreightPickupResponse.PickupRequestConfirmationNumber
+		return err
+	})
+	if err != nil {
+		return
+	}
+
+	//cancel the pickup
+	err = report.run("cancel pickup", func() error {
+		_, err := c.CancelPickup(confirmationNumber)
+		return err
+	})
+	return
+}
+
+//verifyConfirmationNumber is the made up confirmation number VerifyCredentials cancels
+//It is a valid format so UPS checks the credentials and then looks for the pickup, which never exists.
+const verifyConfirmationNumber = "VERIFYCREDENTIALS0"
+
+//VerifyCredentials checks that UPS accepts the username, password, and access key
+//This cancels a made up pickup, which can never cancel a real pickup, since UPS checks the credentials
+//before anything else and the pickup API has no call that only checks credentials.  The call shows up as
+//a CancelPickup in audits and metrics.  Nil is returned if UPS accepted the credentials, UPS not finding
+//the pickup is expected.
+//If UPS rejected the credentials the error matches ErrCredentialsRejected, and ErrInvalidAccessKey or
+//ErrInvalidLogin if UPS said which credential was wrong, use errors.Is to check.  UPS does not say when
+//the access key belongs to a different UPS account than the username, this is reported as a general
+//authentication failure that only matches ErrCredentialsRejected, so check that the access key was
+//created under the same login.  Any other failure, i.e. UPS can't be reached, is returned as is.
+func (c *Client) VerifyCredentials() error {
+	const op = "upsfreight.VerifyCredentials"
+
+	_, err := c.CancelPickup(verifyConfirmationNumber)
+	if err == nil {
+		return nil
+	}
+
+	var fault *UPSFaultError
+	if !errors.As(err, &fault) {
+		return wrapError(err, op, "could not verify credentials")
+	}
+
+	switch {
+	case fault.Is(ErrInvalidAccessKey):
+		return wrapError(err, op, "the access key was rejected")
+	case fault.Is(ErrInvalidLogin):
+		return wrapError(err, op, "the username or password was rejected")
+	case fault.Is(ErrCredentialsRejected):
+		return wrapError(err, op, "the credentials were rejected, the access key may belong to a different UPS account than the username")
+	}
+
+	//UPS got past the credentials so they are good
+	return nil
+}
+
+//samplePickup returns the details of a benign pickup used for testing
+func samplePickup() (prd PickupRequestDetails) {
+	prd.SetCustomerContext("upsfreight-selftest")
+	prd.AdditionalComments = "upsfreight self test, please ignore"
+	prd.DestinationPostalCode = "30328"
+	prd.DestinationCountryCode = "US"
+
+	prd.Requester = Requester{
+		AttentionName: "Self Test",
+		EMailAddress:  "selftest@example.com",
+		Name:          "upsfreight",
+		Phone: PhoneNum{
+			Number: "5555555555",
+		},
+	}
+
+	prd.ShipFrom = ShipFromAddress{
+		AttentionName: "Self Test",
+		Name:          "upsfreight",
+		Address: Address{
+			AddressLine:       "1000 Semmes Ave",
+			City:              "Richmond",
+			StateProvinceCode: "VA",
+			PostalCode:        "23224",
+			CountryCode:       "US",
+		},
+		Phone: PhoneNum{
+			Number: "5555555555",
+		},
+	}
+
+	prd.ShipmentDetail = ShipmentDetail{
+		PackagingType: PackagingType{
+			Code:        "SKD",
+			Description: "Skid",
+		},
+		NumberOfPieces:         "1",
+		DescriptionOfCommodity: "self test",
+		Weight: Weight{
+			Value: "500",
+		},
+	}
+
+	return
+}
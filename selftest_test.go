@@ -0,0 +1,74 @@
+package upsfreight
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestVerifyCredentials(t *testing.T) {
+	//UPS not finding the made up pickup means it accepted the credentials
+	for _, fixture := range []string{"cancel_not_found.json", "cancel_success.json"} {
+		c := newTestClient(t, fixtureHandler(t, fixture))
+		if err := c.VerifyCredentials(); err != nil {
+			t.Errorf("%s: expected the credentials to be accepted, got %v", fixture, err)
+		}
+	}
+}
+
+func TestVerifyCredentialsRejected(t *testing.T) {
+	tests := []struct {
+		code      string
+		accessKey bool
+		login     bool
+	}{
+		{"250003", true, false},
+		{"250004", false, true},
+		{"250002", false, false},
+	}
+
+	fault := readFixture(t, "auth_fault.json")
+	for _, tt := range tests {
+		body := bytes.Replace(fault, []byte("250003"), []byte(tt.code), 1)
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Write(body)
+		})
+
+		err := c.VerifyCredentials()
+		if !errors.Is(err, ErrCredentialsRejected) {
+			t.Errorf("%s: expected ErrCredentialsRejected, got %v", tt.code, err)
+		}
+		if errors.Is(err, ErrInvalidAccessKey) != tt.accessKey {
+			t.Errorf("%s: expected ErrInvalidAccessKey %t, got %v", tt.code, tt.accessKey, err)
+		}
+		if errors.Is(err, ErrInvalidLogin) != tt.login {
+			t.Errorf("%s: expected ErrInvalidLogin %t, got %v", tt.code, tt.login, err)
+		}
+	}
+}
+
+func TestVerifyCredentialsNetworkError(t *testing.T) {
+	c := NewClient("testuser", "testpassword", "testaccesskey")
+	c.SetTransport(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	}))
+
+	err := c.VerifyCredentials()
+	if err == nil || errors.Is(err, ErrCredentialsRejected) {
+		t.Fatalf("expected a network error, not rejected credentials, got %v", err)
+	}
+
+	var fault *UPSFaultError
+	if errors.As(err, &fault) {
+		t.Fatalf("expected a network error, not a fault, got %v", err)
+	}
+}
+
+func TestVerifyCredentialsOtherFault(t *testing.T) {
+	//any other fault means UPS got past the credentials
+	c := newTestClient(t, fixtureHandler(t, "pickup_fault.json"))
+	if err := c.VerifyCredentials(); err != nil {
+		t.Fatalf("expected the credentials to be accepted, got %v", err)
+	}
+}
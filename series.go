@@ -0,0 +1,149 @@
+package upsfreight
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+//PickupSeries is the pattern for a set of recurring, or standing, pickups
+//UPS Freight does not support recurring pickups natively so a series is scheduled as individual
+//pickups, one for each matching date, by SchedulePickupSeries.
+type PickupSeries struct {
+	Days              []time.Weekday //the days of the week to schedule a pickup on
+	StartDate         time.Time      //the first date a pickup can be scheduled on; the location of this time is used for the pickup times
+	EndDate           time.Time      //the last date a pickup can be scheduled on, inclusive
+	EarliestTimeReady string         //24 hour time, HHMM
+	LatestTimeReady   string         //24 hour time, HHMM
+}
+
+//PickupSeriesResult is the outcome of scheduling a series of pickups
+type PickupSeriesResult struct {
+	Pickups []SeriesPickup
+}
+
+//SeriesPickup is the outcome of scheduling one pickup in a series
+//Err is set if this pickup could not be scheduled, otherwise Response holds the confirmation.
+type SeriesPickup struct {
+	Date     time.Time
+	Response PickupRequestResponse
+	Err      error
+}
+
+//Failed returns the pickups in the series that could not be scheduled
+func (r PickupSeriesResult) Failed() (failed []SeriesPickup) {
+	for _, p := range r.Pickups {
+		if p.Err != nil {
+			failed = append(failed, p)
+		}
+	}
+
+	return
+}
+
+//SchedulePickupSeries schedules a pickup for each date matching the series pattern
+//This is done client side, one RequestPickup per date, since UPS does not have a recurring pickup
+//endpoint.  The pickup details are used as a template for each pickup.  If a customer context is set,
+//each pickup gets the customer context with the date appended (YYYYMMDD) so each pickup is unique.
+//Scheduling continues even if a pickup fails.  An error is returned if any pickup failed, check the
+//result for which ones.
+//
+//Deprecated: use Client.SchedulePickupSeries, with a client from NewClient, instead.
+func (prd *PickupRequestDetails) SchedulePickupSeries(series PickupSeries) (PickupSeriesResult, error) {
+	return defaultClient().SchedulePickupSeries(prd, series)
+}
+
+//SchedulePickupSeries schedules a pickup for each date matching the series pattern
+//See PickupRequestDetails.SchedulePickupSeries for details.
+func (c *Client) SchedulePickupSeries(prd *PickupRequestDetails, series PickupSeries) (result PickupSeriesResult, err error) {
+	//check the pattern
+	if len(series.Days) == 0 {
+		err = newError("upsfreight.SchedulePickupSeries", "no days of the week provided")
+		return
+	}
+	if series.EndDate.Before(series.StartDate) {
+		err = newError("upsfreight.SchedulePickupSeries", "EndDate is before StartDate")
+		return
+	}
+
+	earliestHour, earliestMinute, err := parseHHMM(series.EarliestTimeReady)
+	if err != nil {
+		err = wrapError(err, "upsfreight.SchedulePickupSeries", "invalid EarliestTimeReady")
+		return
+	}
+	latestHour, latestMinute, err := parseHHMM(series.LatestTimeReady)
+	if err != nil {
+		err = wrapError(err, "upsfreight.SchedulePickupSeries", "invalid LatestTimeReady")
+		return
+	}
+
+	//get the days to schedule on
+	days := map[time.Weekday]bool{}
+	for _, d := range series.Days {
+		days[d] = true
+	}
+
+	//schedule each pickup
+	loc := series.StartDate.Location()
+	y, m, d := series.StartDate.Date()
+	date := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	baseContext := prd.Request.TransactionReference.CustomerContext
+
+	for !date.After(series.EndDate) {
+		if days[date.Weekday()] {
+			p := SeriesPickup{
+				Date: date,
+			}
+
+			pickup := prd.Clone()
+			if baseContext != "" {
+				pickup.SetCustomerContext(baseContext + "-" + date.Format("20060102"))
+			}
+
+			y, m, d := date.Date()
+			start := time.Date(y, m, d, earliestHour, earliestMinute, 0, 0, loc)
+			end := time.Date(y, m, d, latestHour, latestMinute, 0, 0, loc)
+
+			p.Err = c.SetPickupSchedule(pickup, start, end)
+			if p.Err == nil {
+				p.Response, p.Err = c.RequestPickup(pickup)
+			}
+
+			result.Pickups = append(result.Pickups, p)
+		}
+
+		date = date.AddDate(0, 0, 1)
+	}
+
+	//check if anything failed
+	if failed := len(result.Failed()); failed > 0 {
+		err = newError("upsfreight.SchedulePickupSeries", strconv.Itoa(failed)+" of "+strconv.Itoa(len(result.Pickups))+" pickups failed")
+		return
+	}
+
+	return
+}
+
+//parseHHMM parses a 24 hour time in HHMM format into its hour and minute
+func parseHHMM(s string) (hour, minute int, err error) {
+	if len(s) != 4 || strings.Trim(s, "0123456789") != "" {
+		err = errors.New("time must be 4 digits, HHMM")
+		return
+	}
+
+	hour, err = strconv.Atoi(s[:2])
+	if err != nil || hour < 0 || hour > 23 {
+		err = errors.New("hour must be 00 to 23")
+		return
+	}
+
+	minute, err = strconv.Atoi(s[2:])
+	if err != nil || minute < 0 || minute > 59 {
+		err = errors.New("minute must be 00 to 59")
+		return
+	}
+
+	return
+}
@@ -0,0 +1,139 @@
+package upsfreight
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+//seriesDays is Monday, Wednesday, and Friday from January 14, 2030, a Monday, to January 25, 2030
+var seriesDays = []string{"20300114", "20300116", "20300118", "20300121", "20300123", "20300125"}
+
+//testSeries returns a series of pickups on seriesDays, weekends and Tuesdays and Thursdays are skipped
+func testSeries() PickupSeries {
+	return PickupSeries{
+		Days:              []time.Weekday{time.Monday, time.Wednesday, time.Friday},
+		StartDate:         time.Date(2030, 1, 14, 0, 0, 0, 0, time.Local),
+		EndDate:           time.Date(2030, 1, 25, 0, 0, 0, 0, time.Local),
+		EarliestTimeReady: "1000",
+		LatestTimeReady:   "1400",
+	}
+}
+
+//seriesHandler replies with a fault for the pickups on failDate and pickup_success.json for the rest
+//The pickup date of each request is recorded, in order.
+func seriesHandler(t *testing.T, failDate string) (http.HandlerFunc, func() []string) {
+	var mu sync.Mutex
+	var dates []string
+
+	success := readFixture(t, "pickup_success.json")
+	fault := readFixture(t, "pickup_fault.json")
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var req PickupRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		details := req.FreightPickupRequest
+
+		if !strings.HasSuffix(details.Request.TransactionReference.CustomerContext, "-"+details.PickupDate) {
+			t.Errorf("expected the customer context to end with the pickup date, got %s", details.Request.TransactionReference.CustomerContext)
+		}
+
+		mu.Lock()
+		dates = append(dates, details.PickupDate)
+		mu.Unlock()
+
+		if details.PickupDate == failDate {
+			w.Write(fault)
+			return
+		}
+		w.Write(success)
+	}
+
+	return handler, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), dates...)
+	}
+}
+
+func TestSchedulePickupSeries(t *testing.T) {
+	handler, requested := seriesHandler(t, "")
+	c := newTestClient(t, handler)
+
+	prd := fixedPickup()
+	prd.SetCustomerContext("standing")
+	result, err := c.SchedulePickupSeries(&prd, testSeries())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(requested(), ","); got != strings.Join(seriesDays, ",") {
+		t.Fatalf("expected pickups on %v, got %s", seriesDays, got)
+	}
+	if len(result.Pickups) != len(seriesDays) || len(result.Failed()) != 0 {
+		t.Fatalf("expected %d successful pickups, got %+v", len(seriesDays), result.Pickups)
+	}
+	for i, p := range result.Pickups {
+		if p.Date.Format("20060102") != seriesDays[i] || p.Response.FreightPickupResponse.PickupRequestConfirmationNumber == "" {
+			t.Errorf("pickup %d: expected a confirmed pickup on %s, got %s %+v", i, seriesDays[i], p.Date, p.Response)
+		}
+	}
+
+	//the template is not changed
+	if prd.Request.TransactionReference.CustomerContext != "standing" || prd.PickupDate != "20300115" {
+		t.Errorf("expected the template to not be changed, got %s on %s", prd.Request.TransactionReference.CustomerContext, prd.PickupDate)
+	}
+}
+
+func TestSchedulePickupSeriesPartialFailure(t *testing.T) {
+	handler, requested := seriesHandler(t, "20300118")
+	c := newTestClient(t, handler)
+
+	prd := fixedPickup()
+	prd.SetCustomerContext("standing")
+	result, err := c.SchedulePickupSeries(&prd, testSeries())
+	if err == nil || !strings.Contains(err.Error(), "1 of 6 pickups failed") {
+		t.Fatalf("expected 1 of 6 pickups to fail, got %v", err)
+	}
+
+	//the pickups after the failure are still scheduled
+	if got := requested(); len(got) != len(seriesDays) {
+		t.Fatalf("expected every pickup to be requested, got %v", got)
+	}
+
+	failed := result.Failed()
+	if len(failed) != 1 || failed[0].Date.Format("20060102") != "20300118" {
+		t.Fatalf("expected the pickup on 20300118 to fail, got %+v", failed)
+	}
+
+	var fault *UPSFaultError
+	if !errors.As(failed[0].Err, &fault) {
+		t.Errorf("expected the failed pickup's *UPSFaultError, got %v", failed[0].Err)
+	}
+}
+
+func TestSchedulePickupSeriesInvalidPattern(t *testing.T) {
+	c := newTestClient(t, fixtureHandler(t, "pickup_success.json"))
+	prd := fixedPickup()
+
+	noDays := testSeries()
+	noDays.Days = nil
+
+	backwards := testSeries()
+	backwards.StartDate, backwards.EndDate = backwards.EndDate, backwards.StartDate
+
+	badTime := testSeries()
+	badTime.LatestTimeReady = "2400"
+
+	for name, series := range map[string]PickupSeries{"no days": noDays, "end before start": backwards, "bad time": badTime} {
+		result, err := c.SchedulePickupSeries(&prd, series)
+		if err == nil || len(result.Pickups) != 0 {
+			t.Errorf("%s: expected an error without scheduling any pickups, got %v", name, err)
+		}
+	}
+}
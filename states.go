@@ -0,0 +1,77 @@
+package upsfreight
+
+import (
+	"sort"
+	"strings"
+)
+
+//stateProvinceCodes is the list of two character state and province codes UPS accepts, by country
+//To add a code, just add it to the list for the correct country.
+var stateProvinceCodes = map[string][]string{
+	"US": {
+		//states
+		"AL", "AK", "AZ", "AR", "CA", "CO", "CT", "DE", "FL", "GA",
+		"HI", "ID", "IL", "IN", "IA", "KS", "KY", "LA", "ME", "MD",
+		"MA", "MI", "MN", "MS", "MO", "MT", "NE", "NV", "NH", "NJ",
+		"NM", "NY", "NC", "ND", "OH", "OK", "OR", "PA", "RI", "SC",
+		"SD", "TN", "TX", "UT", "VT", "VA", "WA", "WV", "WI", "WY",
+
+		//district of columbia
+		"DC",
+
+		//territories
+		"AS", "FM", "GU", "MH", "MP", "PR", "PW", "VI",
+	},
+	"CA": {
+		//provinces
+		"AB", "BC", "MB", "NB", "NL", "NS", "ON", "PE", "QC", "SK",
+
+		//territories
+		"NT", "NU", "YT",
+	},
+}
+
+//ValidStateProvinceCodes returns the list of US and Canada state, territory, and province codes
+//The list is sorted alphabetically.  Use this for building dropdowns and such.
+func ValidStateProvinceCodes() []string {
+	codes := []string{}
+	for _, list := range stateProvinceCodes {
+		codes = append(codes, list...)
+	}
+
+	sort.Strings(codes)
+	return codes
+}
+
+//IsValidStateProvinceCode checks if a code is a known US or Canada state, territory, or province code
+//The check is not case sensitive.
+func IsValidStateProvinceCode(code string) bool {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	for _, list := range stateProvinceCodes {
+		for _, c := range list {
+			if c == code {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+//isValidStateProvinceCodeForCountry checks if a code is valid for a given country
+//This returns true for countries we do not have a list of codes for since we cannot check them.
+func isValidStateProvinceCodeForCountry(code, country string) bool {
+	list, ok := stateProvinceCodes[strings.ToUpper(strings.TrimSpace(country))]
+	if !ok {
+		return true
+	}
+
+	code = strings.ToUpper(strings.TrimSpace(code))
+	for _, c := range list {
+		if c == code {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,76 @@
+package upsfreight
+
+import (
+	"context"
+)
+
+//Tracer starts a span for each call to UPS
+//Implement this to connect calls to UPS to your tracing system, i.e. OpenTelemetry, without this
+//package depending on it.  For OpenTelemetry, Start would call tracer.Start(ctx, "upsfreight."+op) and
+//return a Span that sets attributes with span.SetAttributes(attribute.String(key, value)) and, in End,
+//records the error and calls span.End().  The context returned by Start is used for the http request
+//so an instrumented transport, see SetTransport, can attach child spans.
+type Tracer interface {
+	//Start begins a span for an operation, i.e. RequestPickup or CancelPickup
+	Start(ctx context.Context, op string) (context.Context, Span)
+}
+
+//Span is a single traced operation started by a Tracer
+type Span interface {
+	//SetAttribute records information about the operation, see the Attribute constants for the keys used
+	SetAttribute(key, value string)
+
+	//End finishes the span, err is nil when the operation was successful
+	End(err error)
+}
+
+//attribute keys set on spans
+const (
+	AttributeOperation          = "upsfreight.operation"
+	AttributeHTTPStatusCode     = "http.status_code"
+	AttributeConfirmationNumber = "upsfreight.confirmation_number"
+	AttributeTransactionID      = "upsfreight.transaction_id"
+)
+
+//SetTracer saves the tracer used to start a span for each call to UPS
+//Set to nil to stop tracing, this is the default.  Use RequestPickupContext and CancelPickupContext so
+//the spans are children of your own spans.
+func (c *Client) SetTracer(t Tracer) {
+	c.tracer = t
+	return
+}
+
+//spanKey is the context key the current span is saved under
+type spanKey struct{}
+
+//noopSpan is used when no tracer is set so callers don't need to check for nil
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(key, value string) {}
+func (noopSpan) End(err error)                  {}
+
+//startSpan starts a span for an operation using the client's tracer
+//The span is started from ctx, the caller's context, so it is a child of the caller's span.  The span is
+//saved in the returned context so callUPS can add the http details to it.
+func (c *Client) startSpan(ctx context.Context, op string) (context.Context, Span) {
+	if c.tracer == nil {
+		return ctx, noopSpan{}
+	}
+
+	ctx, span := c.tracer.Start(ctx, op)
+	if span == nil {
+		span = noopSpan{}
+	}
+	span.SetAttribute(AttributeOperation, op)
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+//spanFromContext returns the span saved in a context by startSpan
+func spanFromContext(ctx context.Context) Span {
+	if span, ok := ctx.Value(spanKey{}).(Span); ok {
+		return span
+	}
+
+	return noopSpan{}
+}
@@ -0,0 +1,90 @@
+package upsfreight
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+//parentKey is the context key the caller's span is saved under in the tests
+type parentKey struct{}
+
+//recordingTracer records the parent of each span it starts and the attributes set on the spans
+type recordingTracer struct {
+	parents    []interface{}
+	attributes map[string]string
+	ended      bool
+}
+
+func (rt *recordingTracer) Start(ctx context.Context, op string) (context.Context, Span) {
+	rt.parents = append(rt.parents, ctx.Value(parentKey{}))
+	return ctx, rt
+}
+
+func (rt *recordingTracer) SetAttribute(key, value string) {
+	if rt.attributes == nil {
+		rt.attributes = make(map[string]string)
+	}
+	rt.attributes[key] = value
+}
+
+func (rt *recordingTracer) End(err error) {
+	rt.ended = true
+}
+
+func TestSpanParentFromCallerContext(t *testing.T) {
+	tracer := &recordingTracer{}
+	c := newTestClient(t, fixtureHandler(t, "pickup_success.json"))
+	c.SetTracer(tracer)
+
+	ctx := context.WithValue(context.Background(), parentKey{}, "caller span")
+	prd := testPickup(t)
+	if _, err := c.RequestPickupContext(ctx, &prd); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tracer.parents) != 1 || tracer.parents[0] != "caller span" {
+		t.Fatalf("expected the span to be started from the caller's context, got %v", tracer.parents)
+	}
+	if !tracer.ended || tracer.attributes[AttributeOperation] != "RequestPickup" || tracer.attributes[AttributeHTTPStatusCode] != "200" {
+		t.Fatalf("expected an ended span with the operation and status code, got %v", tracer.attributes)
+	}
+
+	//without a context the span has no parent
+	if _, err := c.RequestPickup(&prd); err != nil {
+		t.Fatal(err)
+	}
+	if len(tracer.parents) != 2 || tracer.parents[1] != nil {
+		t.Fatalf("expected a root span without a context, got %v", tracer.parents)
+	}
+}
+
+func TestCancelPickupSpanParentFromCallerContext(t *testing.T) {
+	tracer := &recordingTracer{}
+	c := newTestClient(t, fixtureHandler(t, "cancel_success.json"))
+	c.SetTracer(tracer)
+
+	ctx := context.WithValue(context.Background(), parentKey{}, "caller span")
+	if _, err := c.CancelPickupContext(ctx, "WBU2805291"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tracer.parents) != 1 || tracer.parents[0] != "caller span" {
+		t.Fatalf("expected the span to be started from the caller's context, got %v", tracer.parents)
+	}
+	if tracer.attributes[AttributeConfirmationNumber] != "WBU2805291" {
+		t.Fatalf("expected the confirmation number on the span, got %v", tracer.attributes)
+	}
+}
+
+func TestRequestPickupContextCancelled(t *testing.T) {
+	c := newTestClient(t, fixtureHandler(t, "pickup_success.json"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	prd := testPickup(t)
+	if _, err := c.RequestPickupContext(ctx, &prd); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the call to be cancelled with the caller's context, got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package upsfreight
+
+import (
+	"log"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+//UpdatePickup reschedules a previously requested pickup to a new date and window
+//UPS Freight does not support changing a pickup so this requests a new pickup, with the same details
+//as the original pickup and the new schedule, and then cancels the original pickup.  The new pickup is
+//requested first so the original pickup is kept if the new one can't be scheduled.  If the original
+//pickup can't be cancelled the new pickup is cancelled, rolled back, so only one pickup is ever
+//scheduled.  If the rollback fails as well the error says so and both pickups are scheduled, cancel
+//one of them yourself.  If the original pickup was already cancelled, or UPS does not know of it, the
+//new pickup is kept.  The original pickup is removed from the pickup store if the store implements
+//PickupRemover.
+//UPS does not return the details of a pickup so the original pickup must have been recorded in a pickup
+//store that implements PickupFinder, see SetPickupStore.  The schedule is checked the same as
+//SetPickupSchedule.  The response is for the new pickup, which has a new confirmation number.
+func (c *Client) UpdatePickup(confirmationNumber string, start, end time.Time) (responseData PickupRequestResponse, err error) {
+	const op = "upsfreight.UpdatePickup"
+
+	if !IsValidConfirmationNumber(confirmationNumber) {
+		err = newValidationError(op, "confirmationNumber", "is not a valid confirmation number, it must be 6 to 20 letters and digits")
+		return
+	}
+
+	//look up the original pickup
+	finder, ok := c.pickupStore.(PickupFinder)
+	if !ok {
+		err = newError(op, "pickup store is not set or can't find pickups, UPS does not return pickup details so the original pickup must be recorded, see SetPickupStore")
+		return
+	}
+
+	original, found, err := finder.Find(confirmationNumber)
+	if err != nil {
+		err = wrapError(err, op, "could not look up pickup")
+		return
+	}
+	if !found {
+		err = newError(op, "pickup "+confirmationNumber+" was not recorded in the pickup store")
+		return
+	}
+
+	//build the new pickup
+	//a new customer context is used so the new pickup can be told apart from the original
+	pickup := original.Details.Clone()
+	pickup.GenerateCustomerContext()
+
+	err = c.SetPickupSchedule(pickup, start, end)
+	if err != nil {
+		return
+	}
+
+	//request the new pickup before cancelling the original so the original is kept if this fails
+	responseData, err = c.RequestPickup(pickup)
+	if err != nil {
+		err = wrapError(err, op, "could not request new pickup, the original pickup was not changed")
+		return
+	}
+
+	//cancel the original pickup, rolling back the new pickup if this fails
+	//an original pickup that is already gone, cancelled or unknown to UPS, is what we wanted so the new
+	//pickup is kept, rolling back would leave no pickup at all
+	_, err = c.CancelPickup(confirmationNumber)
+	if err != nil && !errors.Is(err, ErrAlreadyCancelled) && !errors.Is(err, ErrPickupNotFound) {
+		newConfirmationNumber := responseData.FreightPickupResponse.PickupRequestConfirmationNumber
+		responseData = PickupRequestResponse{}
+
+		_, rollbackErr := c.CancelPickup(newConfirmationNumber)
+		if rollbackErr != nil {
+			err = wrapError(err, op, "could not cancel original pickup and could not cancel new pickup "+newConfirmationNumber+" ("+rollbackErr.Error()+"), both pickups are scheduled")
+			return
+		}
+
+		err = wrapError(err, op, "could not cancel original pickup, the new pickup was cancelled")
+		return
+	}
+	err = nil
+
+	//stop listing the original pickup
+	//a failure here is only logged since the pickup was updated and returning an error could cause the
+	//update to be tried again
+	if remover, ok := c.pickupStore.(PickupRemover); ok {
+		if storeErr := remover.Remove(confirmationNumber); storeErr != nil {
+			log.Println(op+" - could not remove original pickup", storeErr)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,158 @@
+package upsfreight
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+//originalPickup is the confirmation number of the pickup being updated
+//The new pickup gets the confirmation number in pickup_success.json, WBU2805291.
+const originalPickup = "WBU1000001"
+
+//updateHandler replies to pickup requests with pickup_success.json and to cancellations with the
+//fixture given for the confirmation number being cancelled
+//The confirmation numbers cancelled are recorded, in order.
+func updateHandler(t *testing.T, cancelFixtures map[string]string) (http.HandlerFunc, func() []string) {
+	var mu sync.Mutex
+	var cancelled []string
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var req CancelPickupRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+
+		number := req.FreightCancelPickupRequest.PickupRequestConfirmationNumber
+		if number == "" {
+			fixtureHandler(t, "pickup_success.json")(w, r)
+			return
+		}
+
+		mu.Lock()
+		cancelled = append(cancelled, number)
+		mu.Unlock()
+
+		fixtureHandler(t, cancelFixtures[number])(w, r)
+	}
+
+	return handler, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), cancelled...)
+	}
+}
+
+//newUpdateClient returns a test client with the original pickup recorded in its pickup store
+func newUpdateClient(t *testing.T, handler http.HandlerFunc) (*Client, *MemoryPickupStore) {
+	c := newTestClient(t, handler)
+
+	store := NewMemoryPickupStore()
+	store.Save(PickupSummary{
+		ConfirmationNumber: originalPickup,
+		RequestedAt:        time.Now(),
+		Details:            fixedPickup(),
+	})
+	c.SetPickupStore(store)
+
+	return c, store
+}
+
+func TestUpdatePickup(t *testing.T) {
+	handler, cancelled := updateHandler(t, map[string]string{
+		originalPickup: "cancel_success.json",
+	})
+	c, store := newUpdateClient(t, handler)
+
+	start, end := testWindow()
+	res, err := c.UpdatePickup(originalPickup, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.FreightPickupResponse.PickupRequestConfirmationNumber != "WBU2805291" {
+		t.Fatalf("expected the new pickup, got %q", res.FreightPickupResponse.PickupRequestConfirmationNumber)
+	}
+	if got := cancelled(); len(got) != 1 || got[0] != originalPickup {
+		t.Fatalf("expected only the original pickup to be cancelled, got %v", got)
+	}
+
+	if _, found, _ := store.Find(originalPickup); found {
+		t.Fatal("expected the original pickup to be removed from the pickup store")
+	}
+	if _, found, _ := store.Find("WBU2805291"); !found {
+		t.Fatal("expected the new pickup to be in the pickup store")
+	}
+}
+
+func TestUpdatePickupOriginalAlreadyGone(t *testing.T) {
+	for _, fixture := range []string{"cancel_already_cancelled.json", "cancel_not_found.json"} {
+		handler, cancelled := updateHandler(t, map[string]string{
+			originalPickup: fixture,
+		})
+		c, store := newUpdateClient(t, handler)
+
+		start, end := testWindow()
+		res, err := c.UpdatePickup(originalPickup, start, end)
+		if err != nil {
+			t.Fatalf("%s: expected the new pickup to be kept, got %v", fixture, err)
+		}
+		if res.FreightPickupResponse.PickupRequestConfirmationNumber != "WBU2805291" {
+			t.Errorf("%s: expected the new pickup, got %q", fixture, res.FreightPickupResponse.PickupRequestConfirmationNumber)
+		}
+		if got := cancelled(); len(got) != 1 || got[0] != originalPickup {
+			t.Errorf("%s: expected the new pickup to not be cancelled, got %v", fixture, got)
+		}
+		if _, found, _ := store.Find(originalPickup); found {
+			t.Errorf("%s: expected the original pickup to be removed from the pickup store", fixture)
+		}
+	}
+}
+
+func TestUpdatePickupRollsBackNewPickup(t *testing.T) {
+	handler, cancelled := updateHandler(t, map[string]string{
+		originalPickup: "pickup_fault.json",
+		"WBU2805291":   "cancel_success.json",
+	})
+	c, store := newUpdateClient(t, handler)
+
+	start, end := testWindow()
+	res, err := c.UpdatePickup(originalPickup, start, end)
+	if err == nil || !strings.Contains(err.Error(), "the new pickup was cancelled") {
+		t.Fatalf("expected the new pickup to be rolled back, got %v", err)
+	}
+	if res.FreightPickupResponse.PickupRequestConfirmationNumber != "" {
+		t.Errorf("expected no response for a rolled back pickup, got %q", res.FreightPickupResponse.PickupRequestConfirmationNumber)
+	}
+	if got := cancelled(); len(got) != 2 || got[0] != originalPickup || got[1] != "WBU2805291" {
+		t.Errorf("expected the original and then the new pickup to be cancelled, got %v", got)
+	}
+
+	var fault *UPSFaultError
+	if !errors.As(err, &fault) {
+		t.Errorf("expected the original cancellation's *UPSFaultError, got %T", err)
+	}
+	if _, found, _ := store.Find(originalPickup); !found {
+		t.Error("expected the original pickup to be kept in the pickup store")
+	}
+}
+
+func TestUpdatePickupRollbackFails(t *testing.T) {
+	handler, cancelled := updateHandler(t, map[string]string{
+		originalPickup: "pickup_fault.json",
+		"WBU2805291":   "pickup_fault.json",
+	})
+	c, _ := newUpdateClient(t, handler)
+
+	start, end := testWindow()
+	_, err := c.UpdatePickup(originalPickup, start, end)
+	if err == nil || !strings.Contains(err.Error(), "both pickups are scheduled") || !strings.Contains(err.Error(), "WBU2805291") {
+		t.Fatalf("expected an error saying both pickups are scheduled, got %v", err)
+	}
+	if got := cancelled(); len(got) != 2 {
+		t.Errorf("expected both pickups to be cancelled, got %v", got)
+	}
+}
@@ -6,95 +6,137 @@ You will need to have a UPS account and register for API access to use this code
 
 Currently this package can perform:
 - pickup requests
+- pickup cancellations (CancelPickup())
+- recurring pickup requests (SchedulePickupSeries())
+- listing scheduled pickups (ListPickups()), when a pickup store is set (SetPickupStore())
 
 To create a pickup request:
 - Set your UPS credentials (SetCredentials()).
-- Set test or production mode (SetProductionMode()).
+- Set test or production mode (SetProductionMode() or SetMode()).
 - Set the packaging type (PackagingType{}).
 - Set the weight of the goods (Weight{}).
 - Create the shipment details (ShipmentDetail{}).
 - Create the pickup details (PickupRequestDetails{}).
-- Set a unique identifier for the pickup request (SetCustomerContext()).
+- Set a unique identifier for the pickup request (SetCustomerContext()), or a new one will be generated
+  for each request and returned in the response (CustomerContext).
 - Set the timeframe for the pickup (SetPickupSchedule()).
+- Validate the pickup details (Validate()) to show every problem at once (ValidateDetailed()).  This is
+  optional since RequestPickup validates the details too and returns a *ValidationError, without
+  calling UPS, if they are invalid.
 - Request the pickup (RequestPickup()).
 - Check for any errors.
+
+The package level funcs (SetCredentials(), RequestPickup(), etc.) use a default client (DefaultClient)
+and are deprecated.  Create a client with NewClient() or NewClientFromFile() and call the same funcs as
+methods on the client instead.  This also lets you use more than one UPS account, or test and production
+mode, at the same time.
+
+Errors returned by this package can be inspected with errors.As.  A *UPSFaultError is returned when UPS
+responds with an error, a *RateLimitError when UPS is rate limiting requests, a *ServiceUnavailableError
+when UPS is down for maintenance (also matched by errors.Is(err, ErrServiceUnavailable)), and a
+*ValidationError when the data provided is missing or invalid.  A *WarningError is returned when UPS
+returned warnings and SetFailOnWarning is on.
 */
 package upsfreight
 
 import (
 	"bytes"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
-	"io/ioutil"
-	"log"
-	"net/http"
+	"strconv"
+	"strings"
 	"time"
-
-	"github.com/pkg/errors"
 )
 
+//Version is the version of this package
+//This is sent to UPS in the User-Agent header and in calendar files, see ICS.  It is updated by hand
+//when a change is released.
+const Version = "1.0.0"
+
+//userAgent identifies this package to UPS
+const userAgent = "upsfreight/" + Version
+
 //api urls
 const (
 	upsTestURL       = "https://wwwcie.ups.com/rest/FreightPickup"
 	upsProductionURL = "https://onlinetools.ups.com/rest/FreightPickup"
 )
 
-//upsURL is set to the test URL by default
-//This is changed to the production URL when the SetProductionMode function is called
-//Forcing the developer to call the SetProductionMode function ensures the production URL is only used
-//when actually needed.
-var upsURL = upsTestURL
-
-//timeout is the default time we should wait for a reply from UPS
-//You may need to adjust this based on how slow connecting to UPS is for you.
-//10 seconds is overly long, but sometimes UPS is very slow.
-var timeout = time.Duration(10 * time.Second)
+//redacted is the value credentials are replaced with in any request data handed back to the user
+const redacted = "REDACTED"
 
 //PickupRequest is the main container struct for data sent to UPS to request a pickup
 //This format, and children types, was determined from UPS API documentation.
 type PickupRequest struct {
-	Security             security
+	Security             Security
 	FreightPickupRequest PickupRequestDetails
 }
 
-//security is the authentication for the request
-//This has two pieces, your UPS website login credential and the API acccess key
-type security struct {
-	UsernameToken struct {
-		Username string //ups website login username
-		Password string //ups website login password
-	}
-	UPSServiceAccessToken struct {
-		AccessLicenseNumber string //api access key from ups
-	}
+//Security is the authentication for the request
+//This has two pieces, your UPS website login credential and the API acccess key.  These are set from
+//the client's credentials, see SetCredentials, when a request is made.
+type Security struct {
+	UsernameToken         UsernameToken
+	UPSServiceAccessToken UPSServiceAccessToken
+}
+
+//UsernameToken is your UPS website login
+type UsernameToken struct {
+	Username string //ups website login username
+	Password string //ups website login password
+}
+
+//UPSServiceAccessToken is your UPS API access key
+type UPSServiceAccessToken struct {
+	AccessLicenseNumber string //api access key from ups
 }
 
 //PickupRequestDetails is the container around the actual pickup request
 //This holds the ship to location, who is making the pickup request, the ship from location,
 //the shipment details, and other pickup information
 type PickupRequestDetails struct {
-	Request struct {
-		TransactionReference struct {
-			CustomerContext string //some unique identifier, time stamp or somethine else unique
-		}
-	}
+	Request RequestHeader
 
-	AdditionalComments     string
+	AdditionalComments     string          `json:",omitempty"` //left out of the request when blank
+	PickupInstructions     string          `json:",omitempty"` //driver facing instructions, i.e. "use rear dock"; left out of the request when blank
 	DestinationPostalCode  string          //the ship to location
 	DestinationCountryCode string          //the ship to location
+	OriginCountryCode      string          `json:"-"` //the ship from country; derived from the ship from address for each request if blank, the details are not changed; not sent to UPS since UPS reads the country from the ship from address
+	AllowDuplicate         bool            `json:"-"` //request the pickup even if it looks like a duplicate, see SetDedupeWindow; not sent to UPS
+	AllowSameLocation      bool            `json:"-"` //allow the ship to and ship from postal codes to be the same, see SetCheckSameLocation; not sent to UPS
+	ConsolidationID        string          `json:"-"` //your id for the orders shipping on this pickup, for your own reconciliation; up to 35 letters, digits, '-', '_', or '.'; not sent to UPS since the pickup request has no reference numbers
 	Requester              Requester       //who is scheduling the pickup
 	ShipFrom               ShipFromAddress //the ship from location
+	ShipTo                 ShipToAddress   `json:"-"` //optional full ship to location; DestinationPostalCode and DestinationCountryCode are derived from this if blank; not sent to UPS since the pickup request only takes the postal and country codes
 	ShipmentDetail         ShipmentDetail  //what is shipping
 	PickupDate             string          //YYYYMMDD; cannot be in the past
 	EarliestTimeReady      string          //24 hour time, HHMM; cannot be in the past
 	LatestTimeReady        string          //24 hour time, HHMM; cannot be in the past
 }
 
+//RequestHeader is the part of every request that identifies the request
+type RequestHeader struct {
+	TransactionReference TransactionReference
+}
+
+//TransactionReference is the identifier of a request, UPS returns it in the response so they can be matched up
+type TransactionReference struct {
+	CustomerContext string //some unique identifier, time stamp or somethine else unique
+}
+
 //Requester is data on who is scheduling the pickup
 type Requester struct {
 	AttentionName string //a person's name or department name
 	EMailAddress  string //for sending pickup request confirmation
 	Name          string //company name where pickup is being made
 	Phone         PhoneNum
+
+	ThirdPartyIndicator string `json:",omitempty"` //set, i.e. "Y", when the requester is scheduling the pickup on behalf of the shipper, i.e. a 3PL or broker; left out of the request when blank
+
+	//FallbackEMailAddress is where to send the confirmation if EMailAddress bounces
+	//UPS only accepts one email address so this is not sent to UPS, it is for your own bounce handling.
+	FallbackEMailAddress string `json:"-"`
 }
 
 //ShipFromAddress is the info on where the shipment is shipping from
@@ -105,29 +147,62 @@ type ShipFromAddress struct {
 	Phone         PhoneNum
 }
 
+//ShipToAddress is the info on where the shipment is shipping to, the consignee
+type ShipToAddress struct {
+	AttentionName string  //a person's name or department name
+	Name          string  //company name of the consignee
+	Address       Address //the address the shipment is delivered to
+	Phone         PhoneNum
+}
+
 //PhoneNum is the container for a phone number
 type PhoneNum struct {
 	Number string
 }
 
 //Address is the container for an address
+//AddressLine2 and AddressLine3 are for suite, floor, or dock details.  When either is set the lines
+//are sent to UPS as a list, otherwise AddressLine is sent on its own, see MarshalJSON.
 type Address struct {
 	AddressLine       string //street
+	AddressLine2      string `json:"-"` //optional, i.e. "Suite 200"; sent with AddressLine
+	AddressLine3      string `json:"-"` //optional, i.e. "Dock 4"; sent with AddressLine
 	City              string
-	StateProvinceCode string //two characters
+	StateProvinceCode string `json:",omitempty"` //two characters; not needed for countries without states or provinces
 	PostalCode        string
 	CountryCode       string //two characters
 }
 
 //ShipmentDetail holds data on the shipment
 type ShipmentDetail struct {
-	HazMatIndicator        string //usually blank
+	HazMatIndicator        string `json:",omitempty"` //usually blank; UPS treats the presence of this field as hazmat so any value, i.e. "Y", marks the shipment as hazmat
 	PackagingType          PackagingType
-	NumberOfPieces         string //must be a string for api to work
+	NumberOfPieces         string    //must be a string for api to work; the total number of pieces, i.e. cartons, see SetPieces
+	HandlingUnits          int       `json:"-"` //the number of handling units, i.e. pallets, the pieces are on; not sent to UPS since the pickup request has no field for it, checked by Validate
+	HandlingUnitWeights    []float64 `json:"-"` //the weight of each handling unit, in the weight's unit, see SetHandlingUnitWeights; not sent to UPS, checked against the total weight by Validate
 	DescriptionOfCommodity string
 	Weight                 Weight
 }
 
+//SetPieces saves the number of handling units (i.e. pallets) and the total number of pieces (i.e. cartons)
+//UPS's pickup request only has a field for the total number of pieces, NumberOfPieces, which is what the
+//total pieces is sent as.  The handling units are kept so Validate can make sure there are not more
+//handling units than pieces.
+func (sd *ShipmentDetail) SetPieces(handlingUnits, totalPieces int) {
+	sd.HandlingUnits = handlingUnits
+	sd.NumberOfPieces = strconv.Itoa(totalPieces)
+	return
+}
+
+//SetHandlingUnitWeights saves the weight of each handling unit and sets the total weight to their sum
+//The number of handling units is set to the number of weights and the total is rounded to two decimal
+//places.  UPS's pickup request only has a field for the total weight so the handling unit weights are
+//not sent to UPS, they are kept so Validate can make sure the total still matches them.
+func (sd *ShipmentDetail) SetHandlingUnitWeights(weights ...float64) {
+	defaultClient().SetHandlingUnitWeights(sd, weights...)
+	return
+}
+
 //PackagingType holds data on what format a shipment is in
 //Skid, boxes, etc.
 //Code is a three character code.  This can be found in the UPS API documentation.
@@ -140,181 +215,464 @@ type PackagingType struct {
 
 //Weight holds data on the weight of the shipment
 type Weight struct {
-	UnitOfMeasurement struct {
-		Code        string //LBS
-		Description string //Pounds
-	}
-	Value string //must be a string for api to work; the actual weight, up to two decimal places
+	UnitOfMeasurement UnitOfMeasurement
+	Value             string //must be a string for api to work; the actual weight, up to two decimal places
+}
+
+//UnitOfMeasurement is the unit a weight is measured in
+type UnitOfMeasurement struct {
+	Code        string //LBS or KGS; pounds are used if blank
+	Description string //Pounds or Kilograms; filled in from the code
+}
+
+//WeightUnit is a unit of weight UPS accepts
+type WeightUnit string
+
+//weight units UPS accepts
+const (
+	WeightUnitPounds    WeightUnit = "LBS"
+	WeightUnitKilograms WeightUnit = "KGS"
+)
+
+//weightUnitDescriptions is the description UPS expects for each weight unit
+var weightUnitDescriptions = map[WeightUnit]string{
+	WeightUnitPounds:    "Pounds",
+	WeightUnitKilograms: "Kilograms",
+}
+
+//poundsPerKilogram converts kilograms to pounds
+const poundsPerKilogram = 2.20462
+
+//SetUnit saves the unit the weight is measured in, WeightUnitPounds or WeightUnitKilograms
+//The description UPS expects is filled in for you.  Validate checks the unit is one UPS accepts.
+func (w *Weight) SetUnit(unit WeightUnit) {
+	w.UnitOfMeasurement.Code = strings.ToUpper(strings.TrimSpace(string(unit)))
+	w.UnitOfMeasurement.Description = weightUnitDescriptions[WeightUnit(w.UnitOfMeasurement.Code)]
+	return
 }
 
 //PickupRequestResponse is the data we get back when a pickup is scheduled successfully
 type PickupRequestResponse struct {
-	FreightPickupResponse struct {
-		Response struct {
-			ResponseStatus struct {
-				Code        string
-				Description string
-			}
-			TransactionReference struct {
-				CustomerContext string
-			}
-		}
-		PickupRequestConfirmationNumber string
+	FreightPickupResponse FreightPickupResponse
+
+	TransactionID   string `json:"-"` //UPS's id for the call, read from the response headers; give this to UPS support when asking about the pickup
+	CustomerContext string `json:"-"` //the unique identifier sent with the request, the one generated for the request if none was set
+}
+
+//FreightPickupResponse is the container around the actual pickup response
+type FreightPickupResponse struct {
+	Response                        Response
+	PickupRequestConfirmationNumber string
+}
+
+//Response is the part of every response from UPS with the outcome of the request
+type Response struct {
+	ResponseStatus       ResponseStatus
+	TransactionReference TransactionReference //the identifier given in the request
+	Alert                Alerts               `json:",omitempty"` //warnings about the request, the request was still successful
+}
+
+//ResponseStatus is the outcome of a request
+//Code is "1" when the request was successful.
+type ResponseStatus struct {
+	Code        string
+	Description string
+}
+
+//Alert is a warning UPS returns along with an otherwise successful response
+type Alert struct {
+	Code        string
+	Description string
+}
+
+//Alerts is the list of warnings UPS returned
+//UPS returns a single object when there is one warning and an array when there are more, this handles both.
+type Alerts []Alert
+
+//UnmarshalJSON decodes either a single alert or a list of alerts
+func (a *Alerts) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		var list []Alert
+		err := json.Unmarshal(data, &list)
+		*a = list
+		return err
 	}
+
+	var single Alert
+	err := json.Unmarshal(data, &single)
+	*a = Alerts{single}
+	return err
+}
+
+//Summary returns a one line description of the response for logging
+//i.e. pickup OK conf=WBU2805291 ctx=order-99 status=Success
+//The number of warnings is included if UPS returned any, and UPS's transaction id if UPS gave one.
+//Credentials are never in a response so they are never in the summary.
+func (r PickupRequestResponse) Summary() string {
+	resp := r.FreightPickupResponse.Response
+
+	status := resp.ResponseStatus.Description
+	if status == "" {
+		status = resp.ResponseStatus.Code
+	}
+
+	summary := "pickup OK" +
+		" conf=" + summaryValue(r.FreightPickupResponse.PickupRequestConfirmationNumber) +
+		" ctx=" + summaryValue(resp.TransactionReference.CustomerContext) +
+		" status=" + summaryValue(status)
+
+	if len(resp.Alert) > 0 {
+		summary += " warnings=" + strconv.Itoa(len(resp.Alert))
+	}
+
+	return summary + transactionIDSummary(r.TransactionID)
+}
+
+//transactionIDSummary returns the transaction id part of a log summary, blank if there is no id
+func transactionIDSummary(id string) string {
+	if id == "" {
+		return ""
+	}
+
+	return " txn=" + summaryValue(id)
+}
+
+//summaryValue formats a value for a log summary
+//Values are quoted if they have spaces, or are blank, so the summary can be split on spaces.
+func summaryValue(v string) string {
+	if v == "" || strings.ContainsAny(v, " \t\n\"") {
+		return strconv.Quote(v)
+	}
+
+	return v
 }
 
 //PickupRequestError is the data we get back from a pickup request when there is an error
 type PickupRequestError struct {
-	Fault struct {
-		FaultCode   string `json:"faultcode"`
-		FaultString string `json:"faultstring"`
-		Detail      struct {
-			Errors struct {
-				ErrorDetail errorDetail
-			}
-		} `json:"detail"`
-	}
+	Fault Fault
+}
+
+//Fault is the error UPS returns when a request fails
+type Fault struct {
+	FaultCode   string      `json:"faultcode"`
+	FaultString string      `json:"faultstring"`
+	Detail      FaultDetail `json:"detail"`
+}
+
+//FaultDetail is the container around the details of a fault
+type FaultDetail struct {
+	Errors FaultErrors
 }
 
-//errorDetail is the actual error message being returned from UPS.
-//an error response can have one or more errorDetails
-type errorDetail struct {
+//FaultErrors is the container around the error message of a fault
+type FaultErrors struct {
+	ErrorDetail ErrorDetail
+}
+
+//ErrorDetail is the actual error message being returned from UPS.
+//an error response can have one or more ErrorDetails
+type ErrorDetail struct {
 	Severity         string
-	PrimaryErrorCode struct {
-		Code        string
-		Description string
-	}
+	PrimaryErrorCode ErrorCode
 }
 
-//apiCredentials is the log in information we will use to make pickup requests
-//this variable is filled by the SetCredentials() func
-var apiCredentials security
+//ErrorCode is a UPS error code and its message
+type ErrorCode struct {
+	Code        string
+	Description string
+}
 
 //SetCredentials saves the login credentials for the UPS website and API so we can make
 //requests
+//
+//Deprecated: use Client.SetCredentials, with a client from NewClient, instead.
 func SetCredentials(username, password, accessKey string) {
-	//web login
-	apiCredentials.UsernameToken.Username = username
-	apiCredentials.UsernameToken.Password = password
-
-	//api access key
-	apiCredentials.UPSServiceAccessToken.AccessLicenseNumber = accessKey
-
+	defaultClient().SetCredentials(username, password, accessKey)
 	return
 }
 
 //SetProductionMode chooses the production url for use
+//Pass false to go back to using the test url.
+//
+//Deprecated: use Client.SetProductionMode, with a client from NewClient, instead.
 func SetProductionMode(yes bool) {
-	upsURL = upsProductionURL
+	defaultClient().SetProductionMode(yes)
+	return
+}
+
+//SetMode chooses the test or production url from a string, such as from an environment variable
+//Accepted values are "test", "sandbox", "production", and "prod", ignoring case.  An error is returned
+//for any other value and the mode is not changed.
+//
+//Deprecated: use Client.SetMode, with a client from NewClient, instead.
+func SetMode(env string) error {
+	return defaultClient().SetMode(env)
+}
+
+//SetBaseURL overrides the url requests are sent to
+//Use this to point requests at a local test server or a proxy.
+//
+//Deprecated: use Client.SetBaseURL, with a client from NewClient, instead.
+func SetBaseURL(url string) {
+	defaultClient().SetBaseURL(url)
 	return
 }
 
 //SetTimeout updates the timeout value to something the user sets
 //use this to increase the timeout if connecting to UPS is really slow
+//
+//Deprecated: use Client.SetTimeout, which takes a time.Duration, instead.
 func SetTimeout(seconds time.Duration) {
-	timeout = time.Duration(seconds * time.Second)
+	defaultClient().SetTimeout(time.Duration(seconds * time.Second))
 	return
 }
 
-//SetCustomerContext saves the unique identifier for this request to the request details
-func (prd *PickupRequestDetails) SetCustomerContext(c string) {
-	prd.Request.TransactionReference.CustomerContext = c
+//SetOperationTimeout updates how long to wait for a reply from UPS for one type of operation
+//op is the name of the func making the call, i.e. "RequestPickup" or "CancelPickup".  Operations
+//without their own timeout use the timeout set with SetTimeout.
+//
+//Deprecated: use Client.SetOperationTimeout, with a client from NewClient, instead.
+func SetOperationTimeout(op string, d time.Duration) {
+	defaultClient().SetOperationTimeout(op, d)
 	return
 }
 
-//SetPickupSchedule sets the date and time range for a pickup
-//This is the time UPS will attempt to perform the pickup
-//Times should be in the future and be on the same date.
-func (prd *PickupRequestDetails) SetPickupSchedule(startTime, endTime time.Time) error {
-	//get date from times and make sure they are the same
-	startYear, startMonth, startDay := startTime.Date()
-	endYear, endMonth, endDay := endTime.Date()
+//SetHeader saves a header that is added to every request sent to UPS
+//See Client.SetHeader for the headers that cannot be set.
+//
+//Deprecated: use Client.SetHeader, with a client from NewClient, instead.
+func SetHeader(key, value string) error {
+	return defaultClient().SetHeader(key, value)
+}
 
-	if (startYear != endYear) || (startMonth != endMonth) || (startDay != endDay) {
-		return errors.New("upsfreight.SetPickupSchedule - startTime and endTime not same date")
-	}
+//OverrideHeader saves a header that replaces, or is added to, the headers on every request sent to UPS
+//See Client.OverrideHeader for details.
+//
+//Deprecated: use Client.OverrideHeader, with a client from NewClient, instead.
+func OverrideHeader(key, value string) {
+	defaultClient().OverrideHeader(key, value)
+	return
+}
 
-	//make sure start time is in the future
-	now := time.Now()
-	if startTime.Sub(now) < 0 {
-		return errors.New("upsfreight.SetPickupSchedule - startTime is in the past")
-	}
+//SetAuditFunc saves a func that is called after every call to UPS
+//See Client.SetAuditFunc for what the func is given and the redaction guarantees.
+//
+//Deprecated: use Client.SetAuditFunc, with a client from NewClient, instead.
+func SetAuditFunc(f func(req, resp []byte, status int)) {
+	defaultClient().SetAuditFunc(f)
+	return
+}
 
-	//make sure end time is after start time
-	//ups also requires a 2 hour window
-	if endTime.Sub(startTime) < time.Hour*2 {
-		return errors.New("upsfreight.SetPickupSchedule - endTime must be at least 2 hours after start time")
-	}
+//SetMetricsFunc saves a func that is called after every call to UPS
+//Use this to collect latency and success/failure counts.  op is the name of the func that was
+//called, i.e. "RequestPickup".  err is nil when the call was successful.
+//Set to nil to stop collecting metrics.
+//
+//Deprecated: use Client.SetMetricsFunc, with a client from NewClient, instead.
+func SetMetricsFunc(f func(op string, dur time.Duration, err error)) {
+	defaultClient().SetMetricsFunc(f)
+	return
+}
 
-	//save date and times
-	prd.PickupDate = startTime.Format("20060102")
-	prd.EarliestTimeReady = startTime.Format("1504")
-	prd.LatestTimeReady = endTime.Format("1504")
-	return nil
+//SetMinimumNotice updates how far in advance of the start of the pickup window a pickup must be scheduled
+//SetPickupSchedule returns an error if the start time is sooner than this.
+//
+//Deprecated: use Client.SetMinimumNotice, with a client from NewClient, instead.
+func SetMinimumNotice(d time.Duration) {
+	defaultClient().SetMinimumNotice(d)
+	return
 }
 
-//RequestPickup performs the call the the UPS API to schedule a pickup
-func (prd *PickupRequestDetails) RequestPickup() (responseData PickupRequestResponse, err error) {
-	//build the PickupRequest struct
-	pickupRequest := PickupRequest{
-		Security:             apiCredentials,
-		FreightPickupRequest: *prd,
+//SetFailOnWarning turns on or off treating warnings from UPS as errors
+//See Client.SetFailOnWarning.
+//
+//Deprecated: use Client.SetFailOnWarning, with a client from NewClient, instead.
+func SetFailOnWarning(yes bool) {
+	defaultClient().SetFailOnWarning(yes)
+	return
+}
+
+//SetMaxResponseSize updates the largest response, in bytes, that will be read from UPS
+//Responses larger than this cause an error instead of being read into memory.
+//
+//Deprecated: use Client.SetMaxResponseSize, with a client from NewClient, instead.
+func SetMaxResponseSize(bytes int64) {
+	defaultClient().SetMaxResponseSize(bytes)
+	return
+}
+
+//SetWeightLimits updates the minimum and maximum weight, in pounds, Validate allows for a shipment
+//Use this if you legitimately ship heavier loads, near truckload weights, than the default allows.
+//RequestPickup validates the details so pickups outside these limits are not requested.
+//
+//Deprecated: use Client.SetWeightLimits, with a client from NewClient, instead.
+func SetWeightLimits(min, max float64) {
+	defaultClient().SetWeightLimits(min, max)
+	return
+}
+
+//SetBeforeSend saves a func that can modify the json of each request right before it is sent to UPS
+//See Client.SetBeforeSend.
+//
+//Deprecated: use Client.SetBeforeSend, with a client from NewClient, instead.
+func SetBeforeSend(f func(body []byte) ([]byte, error)) {
+	defaultClient().SetBeforeSend(f)
+	return
+}
+
+//SetAfterReceive saves a func that can modify the json of each response from UPS before it is decoded
+//See Client.SetAfterReceive.
+//
+//Deprecated: use Client.SetAfterReceive, with a client from NewClient, instead.
+func SetAfterReceive(f func(body []byte) ([]byte, error)) {
+	defaultClient().SetAfterReceive(f)
+	return
+}
+
+//SetStrictDecoding turns on or off rejecting responses from UPS that have unknown fields
+//See Client.SetStrictDecoding.
+//
+//Deprecated: use Client.SetStrictDecoding, with a client from NewClient, instead.
+func SetStrictDecoding(yes bool) {
+	defaultClient().SetStrictDecoding(yes)
+	return
+}
+
+//decodeJSON unmarshals data returned from UPS
+//strict rejects data with fields we do not know about, see SetStrictDecoding.
+func decodeJSON(data []byte, v interface{}, strict bool) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	if strict {
+		d.DisallowUnknownFields()
 	}
 
-	//set measure of weight
-	pickupRequest.FreightPickupRequest.ShipmentDetail.Weight.UnitOfMeasurement.Code = "LBS"
-	pickupRequest.FreightPickupRequest.ShipmentDetail.Weight.UnitOfMeasurement.Description = "Pounds"
+	return d.Decode(v)
+}
+
+//isEmptyConfirmation checks if UPS said a pickup request was successful but didn't give a confirmation number
+func isEmptyConfirmation(r PickupRequestResponse) bool {
+	f := r.FreightPickupResponse
+	return f.Response.ResponseStatus.Code == responseStatusSuccess && f.PickupRequestConfirmationNumber == ""
+}
 
-	//convert the struct to json
-	jsonBytes, err := json.Marshal(pickupRequest)
-	if err != nil {
-		err = errors.Wrap(err, "upsfreight.RequestPickup - could not marshal json")
-		return
+//faultKey is the key an error response from UPS has
+var faultKey = []byte(`"Fault"`)
+
+//isFault checks if data returned from UPS is an error response
+//Most responses are not faults so responses that can't have the key, it isn't there even escaped, are
+//skipped without decoding them.
+func isFault(data []byte) bool {
+	if !bytes.Contains(data, faultKey) && bytes.IndexByte(data, '\\') < 0 {
+		return false
 	}
 
-	//make the call the UPS
-	//set a timeout since golang doesn't set one by default
-	//we don't want this call to hang for too long
-	httpClient := http.Client{
-		Timeout: timeout,
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return false
 	}
-	res, err := httpClient.Post(upsURL, "application/json", bytes.NewReader(jsonBytes))
-	if err != nil {
-		errors.Wrap(err, "upsfreight.RequestPickup - could not make post request")
-		return
+
+	_, ok := probe["Fault"]
+	return ok
+}
+
+//redactedJSON returns the json of a pickup request with the credentials redacted
+//This is used anywhere the request is handed back to the user (auditing, logging, etc.).
+func (pr PickupRequest) redactedJSON() ([]byte, error) {
+	pr.Security.UsernameToken.Password = redacted
+	pr.Security.UPSServiceAccessToken.AccessLicenseNumber = redacted
+	return json.Marshal(pr)
+}
+
+//security returns the credentials the pickup request was built with
+func (pr PickupRequest) security() Security {
+	return pr.Security
+}
+
+//destination returns the ship to postal code and country code
+//DestinationPostalCode and DestinationCountryCode are used if set, otherwise they come from ShipTo.
+func (prd *PickupRequestDetails) destination() (postalCode, countryCode string) {
+	postalCode = prd.DestinationPostalCode
+	if postalCode == "" {
+		postalCode = prd.ShipTo.Address.PostalCode
 	}
 
-	//read the response
-	defer res.Body.Close()
-	body, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		errors.Wrap(err, "upsfreight.RequestPickup - could not read response")
-		return
+	countryCode = prd.DestinationCountryCode
+	if countryCode == "" {
+		countryCode = prd.ShipTo.Address.CountryCode
 	}
 
-	err = json.Unmarshal(body, &responseData)
-	if err != nil {
-		errors.Wrap(err, "upsfreight.RequestPickup - could not unmarshal response")
-		return
+	return
+}
+
+//SetCustomerContext saves the unique identifier for this request to the request details
+//The identifier can be at most MaxCustomerContextLength characters, Validate checks this.
+func (prd *PickupRequestDetails) SetCustomerContext(c string) {
+	prd.Request.TransactionReference.CustomerContext = c
+	return
+}
+
+//Clone returns a deep copy of the pickup request details
+//Use this to build variations of a template pickup, or to give each goroutine its own copy, without
+//changes to the copy affecting the original.
+func (prd *PickupRequestDetails) Clone() *PickupRequestDetails {
+	//copying the struct copies every field that is a value
+	//pointer, slice, and map fields must be copied here so the copy doesn't share them with the original
+	c := *prd
+	c.ShipmentDetail.HandlingUnitWeights = append([]float64(nil), prd.ShipmentDetail.HandlingUnitWeights...)
+	return &c
+}
+
+//GenerateCustomerContext creates a unique identifier for this request and saves it to the request details
+//The identifier is the current UTC time followed by random hex characters, i.e. 20060102150405-1a2b3c4d5e6f7a8b.
+//The identifier is returned so you can store it to correlate the response later.
+func (prd *PickupRequestDetails) GenerateCustomerContext() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+
+	c := time.Now().UTC().Format("20060102150405") + "-" + hex.EncodeToString(b)
+	prd.SetCustomerContext(c)
+	return c
+}
+
+//SetPickupSchedule sets the date and time range for a pickup
+//This is the time UPS will attempt to perform the pickup
+//Times should be in the future and be on the same date.  A *ScheduleError, with a suggested window, is
+//returned if they are not.
+func (prd *PickupRequestDetails) SetPickupSchedule(startTime, endTime time.Time) error {
+	return defaultClient().SetPickupSchedule(prd, startTime, endTime)
+}
+
+//RequestPickup performs the call the the UPS API to schedule a pickup
+//
+//Deprecated: use Client.RequestPickup, with a client from NewClient, instead.
+func (prd *PickupRequestDetails) RequestPickup() (responseData PickupRequestResponse, err error) {
+	return defaultClient().RequestPickup(prd)
+}
+
+//parsePickupResponse decodes the response from UPS to a pickup request
+//A successful response, one with a confirmation number, is returned as the response data.  An error
+//response from UPS, or a response without a confirmation number, is returned as a *UPSFaultError.
+//Data that can't be decoded at all returns an error.  This never panics no matter what data is given.
+//strict rejects responses with fields we do not know about, see SetStrictDecoding.
+func parsePickupResponse(body []byte, strict bool) (responseData PickupRequestResponse, fault *UPSFaultError, err error) {
+	//decode the response
+	//errors are skipped here since in strict mode an error response would not decode into the response data
+	if !isFault(body) {
+		err = decodeJSON(body, &responseData, strict)
+		if err != nil {
+			return
+		}
 	}
 
 	//check if data was returned meaning request was successful
-	//if not, reread the response data and log it
+	//if not, reread the response data as an error
 	if responseData.FreightPickupResponse.PickupRequestConfirmationNumber == "" {
-		log.Println("upsfreight.RequestPickup - pickup request failed")
-		log.Println(string(body))
-
 		var errorData PickupRequestError
 		json.Unmarshal(body, &errorData)
 
-		//return our error so we know where this error came from, and UPS error message so we know what to fix
-		err = errors.New("upsfreight.RequestPickup - pickup request failed")
-		err = errors.Wrap(err, errorData.Fault.Detail.Errors.ErrorDetail.PrimaryErrorCode.Description)
+		fault = newFaultError("upsfreight.RequestPickup", errorData)
 		return
 	}
 
-	//pickup request successful
-	//response data will have confirmation number
-	//an email should also have been sent to the requester email
 	return
 }
@@ -0,0 +1,208 @@
+package upsfreight
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPickupRequestWithoutComments(t *testing.T) {
+	c := NewClient("testuser", "testpassword", "testaccesskey")
+
+	prd := fixedPickup()
+	prd.AdditionalComments = ""
+
+	b, err := json.Marshal(c.buildPickupRequest(&prd))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(b, []byte("AdditionalComments")) {
+		t.Fatalf("expected no AdditionalComments key, got %s", b)
+	}
+
+	checkGolden(t, "pickup_request_no_comments.golden.json", b)
+}
+
+func TestCloneDoesNotShareWithOriginal(t *testing.T) {
+	original := fixedPickup()
+	original.ShipFrom.Address.AddressLine2 = "Suite 200"
+	original.ShipmentDetail.SetHandlingUnitWeights(250, 250)
+	want := fixedPickup()
+	want.ShipFrom.Address.AddressLine2 = "Suite 200"
+	want.ShipmentDetail.SetHandlingUnitWeights(250, 250)
+
+	clone := original.Clone()
+	if !reflect.DeepEqual(*clone, original) {
+		t.Fatalf("expected the clone to equal the original\nclone:    %+v\noriginal: %+v", *clone, original)
+	}
+
+	clone.SetCustomerContext("changed")
+	clone.AdditionalComments = "changed"
+	clone.Requester.Name = "changed"
+	clone.ShipFrom.Address.AddressLine2 = "changed"
+	clone.ShipTo.Address.City = "changed"
+	clone.ShipmentDetail.Weight.Value = "1"
+	clone.ShipmentDetail.HandlingUnitWeights[0] = 1
+	clone.ShipmentDetail.HandlingUnitWeights = append(clone.ShipmentDetail.HandlingUnitWeights, 1)
+	clone.PickupDate = "20300116"
+
+	if !reflect.DeepEqual(original, want) {
+		t.Fatalf("expected the original to be unchanged\ngot:  %+v\nwant: %+v", original, want)
+	}
+}
+
+func TestCloneCopiesEveryReferenceField(t *testing.T) {
+	//Clone has to copy pointer, slice, and map fields itself, fail if one is added without updating Clone
+	copied := map[string]bool{
+		"PickupRequestDetails.ShipmentDetail.HandlingUnitWeights": true,
+	}
+
+	var check func(path string, typ reflect.Type)
+	check = func(path string, typ reflect.Type) {
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			fieldPath := path + "." + f.Name
+
+			switch f.Type.Kind() {
+			case reflect.Struct:
+				check(fieldPath, f.Type)
+			case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface, reflect.Chan, reflect.Func:
+				if !copied[fieldPath] {
+					t.Errorf("%s is a reference type, update Clone to copy it and add it to this test", fieldPath)
+				}
+			}
+		}
+	}
+
+	check("PickupRequestDetails", reflect.TypeOf(PickupRequestDetails{}))
+}
+
+func TestParsePickupResponseSuccess(t *testing.T) {
+	res, fault, err := parsePickupResponse(readFixture(t, "pickup_success.json"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fault != nil {
+		t.Fatalf("expected no fault, got %v", fault)
+	}
+
+	if res.FreightPickupResponse.PickupRequestConfirmationNumber != "WBU2805291" {
+		t.Errorf("expected confirmation number WBU2805291, got %q", res.FreightPickupResponse.PickupRequestConfirmationNumber)
+	}
+	if res.FreightPickupResponse.Response.ResponseStatus.Code != "1" {
+		t.Errorf("expected response status 1, got %q", res.FreightPickupResponse.Response.ResponseStatus.Code)
+	}
+	if res.FreightPickupResponse.Response.TransactionReference.CustomerContext != "upsfreight-selftest" {
+		t.Errorf("expected customer context upsfreight-selftest, got %q", res.FreightPickupResponse.Response.TransactionReference.CustomerContext)
+	}
+}
+
+func TestParsePickupResponseFault(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		_, fault, err := parsePickupResponse(readFixture(t, "pickup_fault.json"), strict)
+		if err != nil {
+			t.Fatalf("strict %t: %v", strict, err)
+		}
+		if fault == nil {
+			t.Fatalf("strict %t: expected a fault", strict)
+		}
+
+		if fault.FaultCode != "Client" || fault.Severity != "Hard" || fault.Code != "9369002" || fault.Description != "Missing or invalid ship from postal code." {
+			t.Errorf("strict %t: fault was not decoded, got %+v", strict, fault)
+		}
+	}
+}
+
+func TestParsePickupResponseMalformed(t *testing.T) {
+	for _, body := range [][]byte{readFixture(t, "pickup_malformed.json"), []byte("<html>Service Unavailable</html>")} {
+		_, fault, err := parsePickupResponse(body, false)
+		if err == nil {
+			t.Errorf("expected an error for %q", body)
+		}
+		if fault != nil {
+			t.Errorf("expected no fault for %q, got %v", body, fault)
+		}
+	}
+}
+
+func FuzzParsePickupResponse(f *testing.F) {
+	for _, name := range []string{"pickup_success.json", "pickup_fault.json", "pickup_malformed.json"} {
+		f.Add(readFixture(f, name))
+	}
+	f.Add([]byte(`{"Fault":null}`))
+	f.Add([]byte(`{"FreightPickupResponse":{"Response":{"Alert":{"Code":"1"}}}}`))
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		for _, strict := range []bool{false, true} {
+			res, fault, err := parsePickupResponse(body, strict)
+			if err == nil && fault == nil && res.FreightPickupResponse.PickupRequestConfirmationNumber == "" {
+				t.Fatalf("strict %t: a response without a confirmation number must be a fault or an error", strict)
+			}
+			if fault != nil && err != nil {
+				t.Fatalf("strict %t: expected a fault or an error, got both", strict)
+			}
+		}
+	})
+}
+
+func TestPickupRequestMarshal(t *testing.T) {
+	c := NewClient("testuser", "testpassword", "testaccesskey")
+
+	prd := fixedPickup()
+	prd.PickupInstructions = "use rear dock"
+	prd.ShipFrom.Address.AddressLine2 = "Suite 200"
+	prd.ShipmentDetail.Weight.SetUnit(WeightUnitKilograms)
+
+	b, err := json.Marshal(c.buildPickupRequest(&prd))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkGolden(t, "pickup_request.golden.json", b)
+}
+
+func TestPickupRequestResponseUnmarshal(t *testing.T) {
+	var res PickupRequestResponse
+	if err := json.Unmarshal(readFixture(t, "pickup_warning.json"), &res); err != nil {
+		t.Fatal(err)
+	}
+
+	r := res.FreightPickupResponse
+	if r.PickupRequestConfirmationNumber != "WBU2805291" {
+		t.Errorf("expected confirmation number WBU2805291, got %q", r.PickupRequestConfirmationNumber)
+	}
+	if r.Response.ResponseStatus.Code != "1" || r.Response.ResponseStatus.Description != "Success" {
+		t.Errorf("expected status 1 Success, got %+v", r.Response.ResponseStatus)
+	}
+	if len(r.Response.Alert) != 2 || r.Response.Alert[1].Description != "Requester phone number is incomplete." {
+		t.Errorf("expected 2 alerts, got %+v", r.Response.Alert)
+	}
+
+	//a single alert is an object instead of a list
+	single := []byte(`{"FreightPickupResponse":{"Response":{"Alert":{"Code":"9369301","Description":"holiday"}}}}`)
+	if err := json.Unmarshal(single, &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.FreightPickupResponse.Response.Alert) != 1 || res.FreightPickupResponse.Response.Alert[0].Code != "9369301" {
+		t.Errorf("expected 1 alert, got %+v", res.FreightPickupResponse.Response.Alert)
+	}
+}
+
+func TestPickupRequestErrorUnmarshal(t *testing.T) {
+	var errorData PickupRequestError
+	if err := json.Unmarshal(readFixture(t, "pickup_fault.json"), &errorData); err != nil {
+		t.Fatal(err)
+	}
+
+	f := errorData.Fault
+	if f.FaultCode != "Client" || f.FaultString != "An exception has been raised as a result of client data." {
+		t.Errorf("expected the fault code and string to be decoded, got %+v", f)
+	}
+
+	detail := f.Detail.Errors.ErrorDetail
+	if detail.Severity != "Hard" || detail.PrimaryErrorCode.Code != "9369002" || detail.PrimaryErrorCode.Description != "Missing or invalid ship from postal code." {
+		t.Errorf("expected the error detail to be decoded, got %+v", detail)
+	}
+}
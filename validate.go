@@ -0,0 +1,269 @@
+package upsfreight
+
+import (
+	"math"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//MaxCustomerContextLength is the longest CustomerContext UPS accepts
+//UPS documents the CustomerContext as 1 to 512 characters.
+const MaxCustomerContextLength = 512
+
+//handlingUnitWeightTolerance is how far, in the weight's unit, the sum of the handling unit weights can
+//be from the total weight
+const handlingUnitWeightTolerance = 1.0
+
+//maxPickupInstructionsLength is the longest PickupInstructions UPS accepts
+const maxPickupInstructionsLength = 500
+
+//maxAddressLineLength is the longest address line UPS accepts
+const maxAddressLineLength = 35
+
+//maxConsolidationIDLength is the longest ConsolidationID allowed
+//This is not sent to UPS, the limit keeps it short enough to store and show alongside a pickup.
+const maxConsolidationIDLength = 35
+
+//Validate checks the pickup request details for missing or invalid data before the request is sent to UPS
+//This catches mistakes locally so you get a clear error instead of a vague fault back from UPS.
+//RequestPickup calls this automatically but you can call it yourself, for example when building the
+//details from user input.  Required fields that are only whitespace are treated as missing.  The error
+//returned is a *ValidationError for the first invalid field, with every invalid field in its Fields.
+func (prd *PickupRequestDetails) Validate() error {
+	return defaultClient().Validate(prd)
+}
+
+//ValidateDetailed checks the pickup request details and returns every invalid field
+//This is for forms where every invalid field should be shown at once.  Nil is returned if the details
+//are valid.  See Validate for details.
+func (prd *PickupRequestDetails) ValidateDetailed() []FieldError {
+	return defaultClient().ValidateDetailed(prd)
+}
+
+//Validate checks the pickup request details for missing or invalid data
+//The client's settings, i.e. weight limits, are used.  See PickupRequestDetails.Validate for details.
+func (c *Client) Validate(prd *PickupRequestDetails) error {
+	fields := c.ValidateDetailed(prd)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	err := newValidationError("upsfreight.Validate", fields[0].Field, fields[0].Message)
+	err.Fields = fields
+	return err
+}
+
+//ValidateDetailed checks the pickup request details and returns every invalid field
+//The client's settings, i.e. weight limits, are used.  See PickupRequestDetails.ValidateDetailed for details.
+func (c *Client) ValidateDetailed(prd *PickupRequestDetails) (fields []FieldError) {
+	invalid := func(field, message string) {
+		fields = append(fields, FieldError{
+			Field:   field,
+			Message: message,
+		})
+	}
+
+	//unique identifier
+	if len(prd.Request.TransactionReference.CustomerContext) > MaxCustomerContextLength {
+		invalid("Request.TransactionReference.CustomerContext", "must be at most "+strconv.Itoa(MaxCustomerContextLength)+" characters")
+	}
+
+	//your id for the pickup
+	if len(prd.ConsolidationID) > maxConsolidationIDLength {
+		invalid("ConsolidationID", "must be at most "+strconv.Itoa(maxConsolidationIDLength)+" characters")
+	} else if strings.Trim(prd.ConsolidationID, "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_.") != "" {
+		invalid("ConsolidationID", "must only be letters, digits, '-', '_', or '.'")
+	}
+
+	//ship to location
+	//this can be given as just the postal and country codes, or as a full address in ShipTo
+	postalCode, countryCode := prd.destination()
+	if isBlank(postalCode) {
+		invalid("DestinationPostalCode", "is required")
+	}
+	if isBlank(countryCode) {
+		invalid("DestinationCountryCode", "is required")
+	}
+
+	to := prd.ShipTo.Address
+	if to.PostalCode != "" && !strings.EqualFold(strings.Replace(to.PostalCode, " ", "", -1), strings.Replace(postalCode, " ", "", -1)) {
+		invalid("DestinationPostalCode", "does not match ShipTo.Address.PostalCode")
+	}
+	if to.CountryCode != "" && !strings.EqualFold(to.CountryCode, countryCode) {
+		invalid("DestinationCountryCode", "does not match ShipTo.Address.CountryCode")
+	}
+	if to.StateProvinceCode != "" && !isValidStateProvinceCodeForCountry(to.StateProvinceCode, to.CountryCode) {
+		invalid("ShipTo.Address.StateProvinceCode", "is not a valid state or province code")
+	}
+
+	//who is scheduling the pickup
+	if isBlank(prd.Requester.Name) {
+		invalid("Requester.Name", "is required")
+	}
+	if isBlank(prd.Requester.EMailAddress) {
+		invalid("Requester.EMailAddress", "is required")
+	}
+	if prd.Requester.FallbackEMailAddress != "" && !isValidEMailAddress(prd.Requester.FallbackEMailAddress) {
+		invalid("Requester.FallbackEMailAddress", "is not a valid email address")
+	}
+
+	//ship from location
+	if isBlank(prd.ShipFrom.Name) {
+		invalid("ShipFrom.Name", "is required")
+	}
+
+	a := prd.ShipFrom.Address
+	if isBlank(a.AddressLine) {
+		invalid("ShipFrom.Address.AddressLine", "is required")
+	}
+	for i, line := range []string{a.AddressLine, a.AddressLine2, a.AddressLine3} {
+		if len(line) > maxAddressLineLength {
+			field := "ShipFrom.Address.AddressLine"
+			if i > 0 {
+				field += strconv.Itoa(i + 1)
+			}
+			invalid(field, "must be at most "+strconv.Itoa(maxAddressLineLength)+" characters")
+		}
+	}
+	if isBlank(a.City) {
+		invalid("ShipFrom.Address.City", "is required")
+	}
+	if isBlank(a.PostalCode) {
+		invalid("ShipFrom.Address.PostalCode", "is required")
+	}
+	if isBlank(a.CountryCode) {
+		invalid("ShipFrom.Address.CountryCode", "is required")
+	}
+	if prd.OriginCountryCode != "" && !strings.EqualFold(prd.OriginCountryCode, a.CountryCode) {
+		invalid("OriginCountryCode", "does not match ShipFrom.Address.CountryCode")
+	}
+	if !isValidStateProvinceCodeForCountry(a.StateProvinceCode, a.CountryCode) {
+		invalid("ShipFrom.Address.StateProvinceCode", "is not a valid state or province code")
+	}
+
+	//shipping to the same place it ships from is usually a mistake
+	if c.checkSameLocation && !prd.AllowSameLocation && !isBlank(postalCode) {
+		samePostalCode := strings.EqualFold(strings.Replace(postalCode, " ", "", -1), strings.Replace(a.PostalCode, " ", "", -1))
+		if samePostalCode && strings.EqualFold(countryCode, a.CountryCode) {
+			invalid("DestinationPostalCode", "is the same as the ship from postal code, set AllowSameLocation if this is correct")
+		}
+	}
+
+	//driver instructions
+	if len(prd.PickupInstructions) > maxPickupInstructionsLength {
+		invalid("PickupInstructions", "must be at most "+strconv.Itoa(maxPickupInstructionsLength)+" characters")
+	}
+
+	//pieces
+	//UPS only knows the total number of pieces, the handling units are only checked if they were given
+	sd := prd.ShipmentDetail
+	if sd.HandlingUnits != 0 {
+		pieces, err := strconv.Atoi(sd.NumberOfPieces)
+		if err != nil {
+			invalid("ShipmentDetail.NumberOfPieces", "is not a whole number")
+		} else if sd.HandlingUnits > pieces {
+			invalid("ShipmentDetail.HandlingUnits", "cannot be more than the total number of pieces, NumberOfPieces")
+		}
+		if sd.HandlingUnits < 0 {
+			invalid("ShipmentDetail.HandlingUnits", "cannot be negative")
+		}
+	}
+
+	//weight of the shipment
+	//this catches unit mistakes, i.e. grams entered instead of pounds
+	//the limits are in pounds so weights in kilograms are converted before checking
+	unit := WeightUnit(strings.ToUpper(strings.TrimSpace(prd.ShipmentDetail.Weight.UnitOfMeasurement.Code)))
+	_, validUnit := weightUnitDescriptions[unit]
+	if unit != "" && !validUnit {
+		invalid("ShipmentDetail.Weight.UnitOfMeasurement.Code", "must be "+string(WeightUnitPounds)+" or "+string(WeightUnitKilograms))
+	}
+
+	//ParseFloat accepts NaN and Inf, NaN would get past the weight limits since it is never less or more
+	//than anything
+	weight, err := strconv.ParseFloat(prd.ShipmentDetail.Weight.Value, 64)
+	validWeight := err == nil && !math.IsNaN(weight) && !math.IsInf(weight, 0)
+	if !validWeight {
+		invalid("ShipmentDetail.Weight.Value", "is not a number")
+	}
+
+	//the handling unit weights must add up to the total weight
+	//a small difference is allowed for rounding
+	if len(sd.HandlingUnitWeights) > 0 {
+		if sd.HandlingUnits != len(sd.HandlingUnitWeights) {
+			invalid("ShipmentDetail.HandlingUnitWeights", "must have one weight for each handling unit")
+		}
+
+		//each bad weight is reported on its own, by index, so the handling unit can be found
+		var sum float64
+		validWeights := true
+		for i, w := range sd.HandlingUnitWeights {
+			if w <= 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+				invalid("ShipmentDetail.HandlingUnitWeights["+strconv.Itoa(i)+"]", "must be more than 0")
+				validWeights = false
+			}
+			sum += w
+		}
+		if validWeights && validWeight && math.Abs(sum-weight) > handlingUnitWeightTolerance {
+			invalid("ShipmentDetail.Weight.Value", "does not match the sum of the handling unit weights, "+formatWeight(sum))
+		}
+	}
+
+	if validWeight && (unit == "" || validUnit) {
+		if unit == WeightUnitKilograms {
+			weight *= poundsPerKilogram
+		}
+		if weight < c.minWeight || weight > c.maxWeight {
+			invalid("ShipmentDetail.Weight.Value", "must be between "+formatWeight(c.minWeight)+" and "+formatWeight(c.maxWeight)+" pounds")
+		}
+	}
+
+	//pickup schedule
+	if isBlank(prd.PickupDate) || isBlank(prd.EarliestTimeReady) || isBlank(prd.LatestTimeReady) {
+		invalid("PickupDate", "is not set, use SetPickupSchedule")
+		return
+	}
+
+	//the schedule can be set directly instead of with SetPickupSchedule so make sure it is usable
+	if _, err := time.Parse("20060102", prd.PickupDate); err != nil {
+		invalid("PickupDate", "must be a date, YYYYMMDD")
+	}
+
+	earliestHour, earliestMinute, earliestErr := parseHHMM(prd.EarliestTimeReady)
+	if earliestErr != nil {
+		invalid("EarliestTimeReady", "is not a valid time, "+earliestErr.Error())
+	}
+	latestHour, latestMinute, latestErr := parseHHMM(prd.LatestTimeReady)
+	if latestErr != nil {
+		invalid("LatestTimeReady", "is not a valid time, "+latestErr.Error())
+	}
+
+	if earliestErr == nil && latestErr == nil {
+		earliest := time.Duration(earliestHour)*time.Hour + time.Duration(earliestMinute)*time.Minute
+		latest := time.Duration(latestHour)*time.Hour + time.Duration(latestMinute)*time.Minute
+		if latest-earliest < minimumPickupWindow {
+			invalid("LatestTimeReady", "must be at least 2 hours after EarliestTimeReady")
+		}
+	}
+
+	return
+}
+
+//isBlank checks if a value is empty or only whitespace
+//UPS treats a value of only spaces as being provided, so these are caught here instead.
+func isBlank(s string) bool {
+	return strings.TrimSpace(s) == ""
+}
+
+//formatWeight formats a weight for use in error messages
+func formatWeight(w float64) string {
+	return strconv.FormatFloat(w, 'f', -1, 64)
+}
+
+//isValidEMailAddress checks if a value is a plain email address, i.e. user@example.com
+//Addresses with a display name, i.e. "Name <user@example.com>", are not accepted.
+func isValidEMailAddress(s string) bool {
+	a, err := mail.ParseAddress(s)
+	return err == nil && a.Address == s && strings.Contains(s[strings.LastIndex(s, "@"):], ".")
+}
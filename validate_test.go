@@ -0,0 +1,242 @@
+package upsfreight
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+//hasFieldError checks if fields has an error for field
+func hasFieldError(fields []FieldError, field string) bool {
+	for _, f := range fields {
+		if f.Field == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestValidateWhitespaceRequiredFields(t *testing.T) {
+	tests := []struct {
+		field string
+		set   func(prd *PickupRequestDetails, value string)
+	}{
+		{"Requester.Name", func(prd *PickupRequestDetails, v string) { prd.Requester.Name = v }},
+		{"Requester.EMailAddress", func(prd *PickupRequestDetails, v string) { prd.Requester.EMailAddress = v }},
+		{"ShipFrom.Name", func(prd *PickupRequestDetails, v string) { prd.ShipFrom.Name = v }},
+		{"ShipFrom.Address.AddressLine", func(prd *PickupRequestDetails, v string) { prd.ShipFrom.Address.AddressLine = v }},
+		{"ShipFrom.Address.City", func(prd *PickupRequestDetails, v string) { prd.ShipFrom.Address.City = v }},
+		{"ShipFrom.Address.PostalCode", func(prd *PickupRequestDetails, v string) { prd.ShipFrom.Address.PostalCode = v }},
+		{"DestinationPostalCode", func(prd *PickupRequestDetails, v string) { prd.DestinationPostalCode = v }},
+		{"PickupDate", func(prd *PickupRequestDetails, v string) { prd.PickupDate = v }},
+		{"PickupDate", func(prd *PickupRequestDetails, v string) { prd.EarliestTimeReady = v }},
+	}
+
+	c := newClient()
+	for _, tt := range tests {
+		for _, value := range []string{"", " ", "   ", "\t", " \t ", "\t\n"} {
+			prd := fixedPickup()
+			tt.set(&prd, value)
+
+			if fields := c.ValidateDetailed(&prd); !hasFieldError(fields, tt.field) {
+				t.Errorf("%s set to %q: expected an error, got %v", tt.field, value, fields)
+			}
+		}
+	}
+}
+
+func TestValidateValidPickup(t *testing.T) {
+	prd := fixedPickup()
+	if fields := newClient().ValidateDetailed(&prd); len(fields) != 0 {
+		t.Fatalf("expected no errors, got %v", fields)
+	}
+}
+
+func TestValidatePickupTimes(t *testing.T) {
+	tests := []struct {
+		earliest, latest string
+		field            string
+	}{
+		//valid
+		{"1000", "1400", ""},
+		{"0000", "2359", ""},
+		{"0800", "1000", ""},
+
+		//malformed
+		{"10:00", "1400", "EarliestTimeReady"},
+		{"100", "1400", "EarliestTimeReady"},
+		{"10000", "1400", "EarliestTimeReady"},
+		{"1o00", "1400", "EarliestTimeReady"},
+		{"-100", "1400", "EarliestTimeReady"},
+		{"2400", "2400", "EarliestTimeReady"},
+		{"1060", "1400", "EarliestTimeReady"},
+		{"1000", "2 PM", "LatestTimeReady"},
+		{"1000", "1475", "LatestTimeReady"},
+		{"1000", "2500", "LatestTimeReady"},
+
+		//out of order or too short
+		{"1400", "1000", "LatestTimeReady"},
+		{"1000", "1000", "LatestTimeReady"},
+		{"1000", "1159", "LatestTimeReady"},
+	}
+
+	c := newClient()
+	for _, tt := range tests {
+		prd := fixedPickup()
+		prd.EarliestTimeReady = tt.earliest
+		prd.LatestTimeReady = tt.latest
+
+		fields := c.ValidateDetailed(&prd)
+		if tt.field == "" {
+			if len(fields) != 0 {
+				t.Errorf("%s to %s: expected no errors, got %v", tt.earliest, tt.latest, fields)
+			}
+			continue
+		}
+
+		if !hasFieldError(fields, tt.field) {
+			t.Errorf("%s to %s: expected an error for %s, got %v", tt.earliest, tt.latest, tt.field, fields)
+		}
+	}
+}
+
+func TestValidatePickupDate(t *testing.T) {
+	c := newClient()
+	for _, date := range []string{"2030-01-15", "20301315", "20300230", "01152030", "2030115"} {
+		prd := fixedPickup()
+		prd.PickupDate = date
+
+		if fields := c.ValidateDetailed(&prd); !hasFieldError(fields, "PickupDate") {
+			t.Errorf("%s: expected an error, got %v", date, fields)
+		}
+	}
+}
+
+//resolveFieldPath checks if a field path, i.e. ShipFrom.Address.PostalCode, is a field in the pickup
+//request details
+//A path can end with an index, i.e. ShipmentDetail.HandlingUnitWeights[2].
+func resolveFieldPath(path string) bool {
+	typ := reflect.TypeOf(PickupRequestDetails{})
+	for _, name := range strings.Split(path, ".") {
+		if i := strings.Index(name, "["); i >= 0 {
+			name = name[:i]
+		}
+
+		f, ok := typ.FieldByName(name)
+		if !ok {
+			return false
+		}
+
+		typ = f.Type
+		if typ.Kind() == reflect.Slice {
+			typ = typ.Elem()
+		}
+	}
+
+	return true
+}
+
+func TestValidateDetailedNestedFields(t *testing.T) {
+	prd := fixedPickup()
+	prd.Requester.FallbackEMailAddress = "not an email"
+	prd.ShipFrom.Address.PostalCode = ""
+	prd.ShipFrom.Address.StateProvinceCode = "ZZ"
+	prd.ShipFrom.Address.AddressLine2 = strings.Repeat("x", 100)
+	prd.ShipTo.Address.CountryCode = "US"
+	prd.ShipTo.Address.StateProvinceCode = "ON"
+	prd.ShipmentDetail.Weight.UnitOfMeasurement.Code = "OZ"
+	prd.ShipmentDetail.SetHandlingUnitWeights(250, -1, 250)
+
+	expected := []string{
+		"Requester.FallbackEMailAddress",
+		"ShipFrom.Address.PostalCode",
+		"ShipFrom.Address.StateProvinceCode",
+		"ShipFrom.Address.AddressLine2",
+		"ShipTo.Address.StateProvinceCode",
+		"ShipmentDetail.Weight.UnitOfMeasurement.Code",
+		"ShipmentDetail.HandlingUnitWeights[1]",
+	}
+
+	fields := newClient().ValidateDetailed(&prd)
+	for _, field := range expected {
+		if !hasFieldError(fields, field) {
+			t.Errorf("expected an error for %s, got %v", field, fields)
+		}
+	}
+
+	for _, f := range fields {
+		if !resolveFieldPath(f.Field) {
+			t.Errorf("%s is not a field in PickupRequestDetails", f.Field)
+		}
+		if f.Message == "" {
+			t.Errorf("%s has no message", f.Field)
+		}
+	}
+
+	//Validate returns every field, the first one is also the error's field
+	err := newClient().Validate(&prd)
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a *ValidationError, got %v", err)
+	}
+	if !reflect.DeepEqual(validationErr.Fields, fields) {
+		t.Errorf("expected the error to have every field\ngot:  %v\nwant: %v", validationErr.Fields, fields)
+	}
+	if validationErr.Field != fields[0].Field || validationErr.Message != fields[0].Message {
+		t.Errorf("expected the error to be for %s, got %s", fields[0].Field, validationErr.Field)
+	}
+	for _, field := range expected {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("expected %s in the error message, got %s", field, err.Error())
+		}
+	}
+}
+
+func TestValidateHandlingUnitWeights(t *testing.T) {
+	prd := fixedPickup()
+	prd.ShipmentDetail.SetHandlingUnitWeights(250, -1, 0, 250, math.NaN(), math.Inf(1))
+
+	fields := newClient().ValidateDetailed(&prd)
+	for _, i := range []string{"1", "2", "4", "5"} {
+		if !hasFieldError(fields, "ShipmentDetail.HandlingUnitWeights["+i+"]") {
+			t.Errorf("expected an error for handling unit %s, got %v", i, fields)
+		}
+	}
+	for _, i := range []string{"0", "3"} {
+		if hasFieldError(fields, "ShipmentDetail.HandlingUnitWeights["+i+"]") {
+			t.Errorf("expected no error for handling unit %s, got %v", i, fields)
+		}
+	}
+}
+
+func TestValidateWeightValue(t *testing.T) {
+	tests := []struct {
+		value string
+		valid bool
+	}{
+		{"500", true},
+		{"1250.5", true},
+		{"", false},
+		{"abc", false},
+		{"0", false},
+		{"-1", false},
+		{"NaN", false},
+		{"Inf", false},
+		{"-Inf", false},
+		{"+Inf", false},
+	}
+
+	c := newClient()
+	for _, tt := range tests {
+		prd := fixedPickup()
+		prd.ShipmentDetail.Weight.Value = tt.value
+
+		if got := !hasFieldError(c.ValidateDetailed(&prd), "ShipmentDetail.Weight.Value"); got != tt.valid {
+			t.Errorf("%q: expected valid %t, got %t", tt.value, tt.valid, got)
+		}
+	}
+}
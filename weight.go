@@ -0,0 +1,192 @@
+package upsfreight
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+//RoundingMode is how weights are rounded to two decimal places when they are added together
+type RoundingMode int
+
+//rounding modes
+const (
+	RoundHalfUp   RoundingMode = iota //halves are rounded away from zero, i.e. 1.005 to 1.01; the default
+	RoundHalfEven                     //halves are rounded to the nearest even number, i.e. 1.005 to 1.00 and 1.015 to 1.02, also called banker's rounding
+)
+
+//weightDecimals is the number of decimal places UPS accepts for a weight
+const weightDecimals = 2
+
+//SetRoundingMode updates how SumWeights, SetHandlingUnitWeights, TotalWeight, and TotalWeightIn round the
+//total weight
+//Use this so the totals calculated here match what your TMS or accounting system calculates.
+func (c *Client) SetRoundingMode(m RoundingMode) {
+	c.roundingMode = m
+	return
+}
+
+//SumWeights adds weights together and rounds the total to two decimal places
+//The weights are added as the decimal numbers they are written as, not as floating point numbers, so
+//0.1 + 0.2 is exactly 0.3 and a total of 1.005 is a half that is rounded based on the rounding mode,
+//see SetRoundingMode.  Use the total for ShipmentDetail.Weight.Value.
+func (c *Client) SumWeights(weights ...float64) float64 {
+	rounded, _ := roundRat(sumDecimal(weights), weightDecimals, c.roundingMode).Float64()
+	return rounded
+}
+
+//SetHandlingUnitWeights saves the weight of each handling unit and sets the total weight to their sum
+//The total is rounded using the client's rounding mode, see SetRoundingMode.  See
+//ShipmentDetail.SetHandlingUnitWeights for details.
+func (c *Client) SetHandlingUnitWeights(sd *ShipmentDetail, weights ...float64) {
+	sd.HandlingUnitWeights = append([]float64(nil), weights...)
+	sd.HandlingUnits = len(weights)
+
+	sd.Weight.Value = roundRat(sumDecimal(weights), weightDecimals, c.roundingMode).FloatString(weightDecimals)
+
+	return
+}
+
+//sumDecimal adds numbers together as the decimal numbers they are written as
+//Each number is converted using the shortest decimal that represents it, i.e. 0.1 instead of the
+//floating point value 0.1000000000000000055511151231257827.
+func sumDecimal(numbers []float64) *big.Rat {
+	total := new(big.Rat)
+	for _, n := range numbers {
+		r, ok := new(big.Rat).SetString(strconv.FormatFloat(n, 'f', -1, 64))
+		if !ok {
+			continue
+		}
+
+		total.Add(total, r)
+	}
+
+	return total
+}
+
+//roundRat rounds a number to the given number of decimal places
+func roundRat(r *big.Rat, decimals int, mode RoundingMode) *big.Rat {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+
+	//scale the number up so rounding to a whole number rounds to the decimal places
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(scale))
+
+	//split into the whole number and the remainder, both with the sign of the number
+	whole, rem := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+
+	//compare twice the remainder to the denominator to know if the remainder is below, at, or above a half
+	twiceRem := new(big.Int).Abs(rem)
+	twiceRem.Mul(twiceRem, big.NewInt(2))
+	cmp := twiceRem.Cmp(scaled.Denom())
+
+	roundAway := cmp > 0
+	if cmp == 0 {
+		switch mode {
+		case RoundHalfEven:
+			roundAway = whole.Bit(0) == 1
+		default:
+			roundAway = true
+		}
+	}
+
+	if roundAway {
+		if scaled.Sign() < 0 {
+			whole.Sub(whole, big.NewInt(1))
+		} else {
+			whole.Add(whole, big.NewInt(1))
+		}
+	}
+
+	return new(big.Rat).SetFrac(whole, scale)
+}
+
+//TotalWeight returns the total weight of the shipment and the unit it is in, as it will be sent to UPS
+//The weight is ShipmentDetail.Weight.Value, rounded to two decimal places, in the unit of the weight,
+//pounds if the unit is blank.  If handling unit weights are set (see SetHandlingUnitWeights) they must
+//add up to the weight, within a pound or kilogram, otherwise an error is returned.  UPS only accepts a
+//single weight unit for a shipment, use TotalWeightIn to add up shipments weighed in different units.
+func (prd *PickupRequestDetails) TotalWeight() (weight float64, unit WeightUnit, err error) {
+	return defaultClient().TotalWeight(prd)
+}
+
+//TotalWeight returns the total weight of the shipment and the unit it is in, as it will be sent to UPS
+//The weight is rounded using the client's rounding mode, see SetRoundingMode.  See
+//PickupRequestDetails.TotalWeight for details.
+func (c *Client) TotalWeight(prd *PickupRequestDetails) (weight float64, unit WeightUnit, err error) {
+	total, unit, err := totalWeight("upsfreight.TotalWeight", prd)
+	if err != nil {
+		return
+	}
+
+	weight, _ = roundRat(total, weightDecimals, c.roundingMode).Float64()
+	return
+}
+
+//TotalWeightIn returns the total weight of the shipment converted to unit
+//Use this to add up shipments weighed in different units, i.e. some in pounds and some in kilograms,
+//in a common unit.  The weight is converted before it is rounded to two decimal places so it isn't
+//rounded twice.  See TotalWeight for the errors returned.
+func (prd *PickupRequestDetails) TotalWeightIn(unit WeightUnit) (float64, error) {
+	return defaultClient().TotalWeightIn(prd, unit)
+}
+
+//TotalWeightIn returns the total weight of the shipment converted to unit
+//The weight is rounded using the client's rounding mode, see SetRoundingMode.  See
+//PickupRequestDetails.TotalWeightIn for details.
+func (c *Client) TotalWeightIn(prd *PickupRequestDetails, unit WeightUnit) (weight float64, err error) {
+	const op = "upsfreight.TotalWeightIn"
+
+	to := WeightUnit(strings.ToUpper(strings.TrimSpace(string(unit))))
+	if _, ok := weightUnitDescriptions[to]; !ok {
+		err = newValidationError(op, "unit", "must be "+string(WeightUnitPounds)+" or "+string(WeightUnitKilograms))
+		return
+	}
+
+	total, from, err := totalWeight(op, prd)
+	if err != nil {
+		return
+	}
+
+	perKilogram, _ := new(big.Rat).SetString(strconv.FormatFloat(poundsPerKilogram, 'f', -1, 64))
+	switch {
+	case from == WeightUnitKilograms && to == WeightUnitPounds:
+		total.Mul(total, perKilogram)
+	case from == WeightUnitPounds && to == WeightUnitKilograms:
+		total.Quo(total, perKilogram)
+	}
+
+	weight, _ = roundRat(total, weightDecimals, c.roundingMode).Float64()
+	return
+}
+
+//totalWeight returns the exact total weight of the shipment and its unit, see TotalWeight
+//op is the name of the func the total is for and is used in error messages.
+func totalWeight(op string, prd *PickupRequestDetails) (total *big.Rat, unit WeightUnit, err error) {
+	w := prd.ShipmentDetail.Weight
+
+	unit = WeightUnit(strings.ToUpper(strings.TrimSpace(w.UnitOfMeasurement.Code)))
+	if unit == "" {
+		unit = WeightUnitPounds
+	}
+	if _, ok := weightUnitDescriptions[unit]; !ok {
+		err = newValidationError(op, "ShipmentDetail.Weight.UnitOfMeasurement.Code", "must be "+string(WeightUnitPounds)+" or "+string(WeightUnitKilograms))
+		return
+	}
+
+	total, ok := new(big.Rat).SetString(strings.TrimSpace(w.Value))
+	if !ok || total.Sign() <= 0 {
+		err = newValidationError(op, "ShipmentDetail.Weight.Value", "must be a number more than 0")
+		return
+	}
+
+	if weights := prd.ShipmentDetail.HandlingUnitWeights; len(weights) > 0 {
+		diff, _ := new(big.Rat).Sub(sumDecimal(weights), total).Float64()
+		if math.Abs(diff) > handlingUnitWeightTolerance {
+			err = newValidationError(op, "ShipmentDetail.Weight.Value", "does not match the sum of the handling unit weights")
+			return
+		}
+	}
+
+	return
+}
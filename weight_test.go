@@ -0,0 +1,168 @@
+package upsfreight
+
+import "testing"
+
+func TestSetHandlingUnitWeightsRoundingMode(t *testing.T) {
+	tests := []struct {
+		mode     RoundingMode
+		expected string
+	}{
+		{RoundHalfUp, "1.01"},
+		{RoundHalfEven, "1.00"},
+	}
+
+	for _, tt := range tests {
+		c := newClient()
+		c.SetRoundingMode(tt.mode)
+
+		var sd ShipmentDetail
+		c.SetHandlingUnitWeights(&sd, 0.505, 0.5)
+		if sd.Weight.Value != tt.expected {
+			t.Errorf("mode %d: expected %s, got %s", tt.mode, tt.expected, sd.Weight.Value)
+		}
+		if sd.HandlingUnits != 2 {
+			t.Errorf("mode %d: expected 2 handling units, got %d", tt.mode, sd.HandlingUnits)
+		}
+	}
+}
+
+func TestTotalWeightRoundingMode(t *testing.T) {
+	tests := []struct {
+		mode     RoundingMode
+		expected float64
+	}{
+		{RoundHalfUp, 1.01},
+		{RoundHalfEven, 1.00},
+	}
+
+	for _, tt := range tests {
+		c := newClient()
+		c.SetRoundingMode(tt.mode)
+
+		var prd PickupRequestDetails
+		prd.ShipmentDetail.Weight.Value = "1.005"
+
+		weight, unit, err := c.TotalWeight(&prd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if weight != tt.expected {
+			t.Errorf("mode %d: expected %v, got %v", tt.mode, tt.expected, weight)
+		}
+		if unit != WeightUnitPounds {
+			t.Errorf("mode %d: expected %s, got %s", tt.mode, WeightUnitPounds, unit)
+		}
+	}
+}
+
+func TestSumWeightsRoundingBoundaries(t *testing.T) {
+	tests := []struct {
+		weights          []float64
+		halfUp, halfEven float64
+	}{
+		//exact halves round differently
+		{[]float64{1.005}, 1.01, 1.00},
+		{[]float64{1.015}, 1.02, 1.02},
+		{[]float64{1.025}, 1.03, 1.02},
+		{[]float64{2.675}, 2.68, 2.68},
+		{[]float64{0.505, 0.5}, 1.01, 1.00},
+		{[]float64{100.125, 200.5}, 300.63, 300.62},
+
+		//just below and above a half round the same in both modes
+		{[]float64{1.0049}, 1.00, 1.00},
+		{[]float64{1.0051}, 1.01, 1.01},
+		{[]float64{1.0149}, 1.01, 1.01},
+		{[]float64{1.0151}, 1.02, 1.02},
+
+		//decimal sums are exact, not floating point
+		{[]float64{0.1, 0.2}, 0.3, 0.3},
+		{[]float64{0.333, 0.333, 0.334}, 1, 1},
+		{[]float64{500, 250.25, 249.75}, 1000, 1000},
+
+		//nothing to add
+		{nil, 0, 0},
+	}
+
+	for _, tt := range tests {
+		c := newClient()
+		if got := c.SumWeights(tt.weights...); got != tt.halfUp {
+			t.Errorf("half up %v: expected %v, got %v", tt.weights, tt.halfUp, got)
+		}
+
+		c.SetRoundingMode(RoundHalfEven)
+		if got := c.SumWeights(tt.weights...); got != tt.halfEven {
+			t.Errorf("half even %v: expected %v, got %v", tt.weights, tt.halfEven, got)
+		}
+	}
+}
+
+func TestTotalWeightInMixedUnits(t *testing.T) {
+	kilograms := fixedPickup()
+	kilograms.ShipmentDetail.Weight.Value = "100"
+	kilograms.ShipmentDetail.Weight.SetUnit(WeightUnitKilograms)
+
+	pounds := fixedPickup()
+	pounds.ShipmentDetail.Weight.Value = "500"
+	pounds.ShipmentDetail.Weight.SetUnit(WeightUnitPounds)
+
+	//a blank unit is pounds
+	blank := fixedPickup()
+	blank.ShipmentDetail.Weight.Value = "500"
+	blank.ShipmentDetail.Weight.UnitOfMeasurement = UnitOfMeasurement{}
+
+	tests := []struct {
+		name     string
+		prd      PickupRequestDetails
+		unit     WeightUnit
+		expected float64
+	}{
+		{"kilograms in pounds", kilograms, WeightUnitPounds, 220.46},
+		{"kilograms in kilograms", kilograms, WeightUnitKilograms, 100},
+		{"pounds in kilograms", pounds, WeightUnitKilograms, 226.8},
+		{"pounds in pounds", pounds, WeightUnitPounds, 500},
+		{"blank in kilograms", blank, "kgs", 226.8},
+	}
+
+	c := newClient()
+	for _, tt := range tests {
+		weight, err := c.TotalWeightIn(&tt.prd, tt.unit)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if weight != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, weight)
+		}
+	}
+
+	//shipments weighed in different units add up in a common unit
+	var total float64
+	for _, prd := range []PickupRequestDetails{kilograms, pounds} {
+		weight, err := c.TotalWeightIn(&prd, WeightUnitPounds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		total = c.SumWeights(total, weight)
+	}
+	if total != 720.46 {
+		t.Errorf("expected 100 kilograms and 500 pounds to be 720.46 pounds, got %v", total)
+	}
+}
+
+func TestTotalWeightInErrors(t *testing.T) {
+	c := newClient()
+
+	prd := fixedPickup()
+	if _, err := c.TotalWeightIn(&prd, "OZ"); err == nil {
+		t.Error("expected an error for an unknown unit")
+	}
+
+	//the handling unit weights are in the shipment's unit, not the unit converted to
+	mismatched := fixedPickup()
+	mismatched.ShipmentDetail.Weight.SetUnit(WeightUnitKilograms)
+	mismatched.ShipmentDetail.SetHandlingUnitWeights(100, 100)
+	mismatched.ShipmentDetail.Weight.Value = "440.92"
+	if _, err := c.TotalWeightIn(&mismatched, WeightUnitPounds); err == nil {
+		t.Error("expected an error when the handling unit weights don't add up to the weight")
+	}
+}